@@ -0,0 +1,116 @@
+package mediator_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Oleexo/mediator-go"
+	"testing"
+)
+
+var benchmarkBehaviorCounts = []int{0, 1, 5}
+
+type benchmarkPipelineBehavior struct {
+}
+
+func (b benchmarkPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+	return next(ctx, request)
+}
+
+type benchmarkNotificationBehavior struct {
+}
+
+func (b benchmarkNotificationBehavior) Handle(ctx context.Context, notification mediator.Notification, next mediator.NotificationNextFunc) error {
+	return next(ctx)
+}
+
+func newBenchmarkSendContainer(behaviors int) mediator.SendContainer {
+	optFns := []func(*mediator.SendContainerOptions){
+		mediator.WithRequestDefinitionHandler(
+			mediator.NewRequestHandlerDefinition[*TestRequest, string](&TestRequestHandler{}),
+		),
+	}
+	for i := 0; i < behaviors; i++ {
+		optFns = append(optFns, mediator.WithContextPipelineBehavior(benchmarkPipelineBehavior{}))
+	}
+	return mediator.NewSendContainer(optFns...)
+}
+
+func newBenchmarkPublishContainer(behaviors int) mediator.PublishContainer {
+	optFns := []func(*mediator.PublishOptions){
+		mediator.WithNotificationDefinitionHandler(
+			mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{}),
+		),
+	}
+	for i := 0; i < behaviors; i++ {
+		optFns = append(optFns, mediator.WithNotificationBehavior(benchmarkNotificationBehavior{}))
+	}
+	return mediator.NewPublishContainer(optFns...)
+}
+
+func BenchmarkSend(b *testing.B) {
+	for _, behaviors := range benchmarkBehaviorCounts {
+		b.Run(fmt.Sprintf("behaviors=%d", behaviors), func(b *testing.B) {
+			container := newBenchmarkSendContainer(behaviors)
+			ctx := context.Background()
+			request := &TestRequest{Value: "test"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := mediator.Send[*TestRequest, string](ctx, container, request); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSender_Send(b *testing.B) {
+	for _, behaviors := range benchmarkBehaviorCounts {
+		b.Run(fmt.Sprintf("behaviors=%d", behaviors), func(b *testing.B) {
+			sender := mediator.NewSender(newBenchmarkSendContainer(behaviors))
+			ctx := context.Background()
+			request := &TestRequest{Value: "test"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := sender.Send(ctx, request); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPublish(b *testing.B) {
+	for _, behaviors := range benchmarkBehaviorCounts {
+		b.Run(fmt.Sprintf("behaviors=%d", behaviors), func(b *testing.B) {
+			container := newBenchmarkPublishContainer(behaviors)
+			ctx := context.Background()
+			notification := TestNotification{Value: "test"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := mediator.Publish(ctx, container, notification); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPublisher_Publish(b *testing.B) {
+	for _, behaviors := range benchmarkBehaviorCounts {
+		b.Run(fmt.Sprintf("behaviors=%d", behaviors), func(b *testing.B) {
+			publisher := mediator.NewPublisher(newBenchmarkPublishContainer(behaviors))
+			ctx := context.Background()
+			notification := TestNotification{Value: "test"}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := publisher.Publish(ctx, notification); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package mediator
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// CallOptions configures a single call of Send or Publish
+type CallOptions struct {
+	// PublishStrategy overrides the publish strategy of the container for a Publish, nil keeps it
+	PublishStrategy PublishStrategy
+	// SkippedBehaviors are the types of the behaviors which are not executed by the call
+	SkippedBehaviors []reflect.Type
+	// PipelineBehaviors are executed by a Send after the pipeline behaviors of the container
+	PipelineBehaviors []ContextPipelineBehavior
+	// NotificationBehaviors are executed by a Publish after the notification behaviors of the container
+	NotificationBehaviors []NotificationBehavior
+	// Timeout bounds the call with a context deadline, 0 keeps the deadline of the context
+	Timeout time.Duration
+	// Metadata is attached to the context of the call, see CallMetadata
+	Metadata map[string]interface{}
+	// Result receives the detailed result of the call, when it is not nil
+	Result *CallResult
+}
+
+// CallResult is the detailed result of a call, see WithCallResult
+type CallResult struct {
+	// Handlers are the handlers the message was dispatched to, in execution order for a notification
+	Handlers []interface{}
+	// Duration is the time spent in the call
+	Duration time.Duration
+	// Err is the error returned by the call
+	Err error
+}
+
+// WithCallPublishStrategy overrides the publish strategy of the container for the call,
+// e.g. to publish a critical notification synchronously
+func WithCallPublishStrategy(strategy PublishStrategy) func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.PublishStrategy = strategy
+	}
+}
+
+// WithoutBehavior skips the pipeline and notification behaviors of type TBehavior for the call
+// When TBehavior is an interface, the behaviors implementing it are skipped
+func WithoutBehavior[TBehavior interface{}]() func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.SkippedBehaviors = append(options.SkippedBehaviors, reflect.TypeOf((*TBehavior)(nil)).Elem())
+	}
+}
+
+// WithCallPipelineBehavior adds a pipeline behavior to the call, after the pipeline behaviors of the container
+func WithCallPipelineBehavior(pipelineBehavior ContextPipelineBehavior) func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.PipelineBehaviors = append(options.PipelineBehaviors, pipelineBehavior)
+	}
+}
+
+// WithCallNotificationBehavior adds a notification behavior to the call, after the notification behaviors of the container
+func WithCallNotificationBehavior(notificationBehavior NotificationBehavior) func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.NotificationBehaviors = append(options.NotificationBehaviors, notificationBehavior)
+	}
+}
+
+// WithCallTimeout bounds the call with a timeout
+// The handlers executed by an asynchronous strategy are not bound by it
+func WithCallTimeout(timeout time.Duration) func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithCallMetadata attaches a metadata to the context of the call, the behaviors and handlers read it with CallMetadata
+func WithCallMetadata(key string, value interface{}) func(*CallOptions) {
+	return func(options *CallOptions) {
+		if options.Metadata == nil {
+			options.Metadata = make(map[string]interface{})
+		}
+		options.Metadata[key] = value
+	}
+}
+
+// WithCallResult fills the result with the details of the call when it returns
+// The async publisher fills it when the notification is queued
+func WithCallResult(result *CallResult) func(*CallOptions) {
+	return func(options *CallOptions) {
+		options.Result = result
+	}
+}
+
+type callMetadataKey struct{}
+
+// CallMetadata returns the metadata attached to the calls of the context, including the enclosing calls
+func CallMetadata(ctx context.Context) map[string]interface{} {
+	metadata, _ := ctx.Value(callMetadataKey{}).(map[string]interface{})
+	return metadata
+}
+
+// call is a Send or a Publish with call options, a nil call has no options
+type call struct {
+	options CallOptions
+	cancel  context.CancelFunc
+	start   time.Time
+}
+
+// newCall applies the call options to the context of the call, it returns a nil call when there are no options
+func newCall(ctx context.Context, optFns []func(*CallOptions)) (*call, context.Context) {
+	if len(optFns) == 0 {
+		return nil, ctx
+	}
+	c := &call{}
+	for _, optFn := range optFns {
+		optFn(&c.options)
+	}
+	if c.options.Result != nil {
+		c.start = time.Now()
+	}
+	if len(c.options.Metadata) > 0 {
+		metadata := make(map[string]interface{}, len(c.options.Metadata))
+		for key, value := range CallMetadata(ctx) {
+			metadata[key] = value
+		}
+		for key, value := range c.options.Metadata {
+			metadata[key] = value
+		}
+		ctx = context.WithValue(ctx, callMetadataKey{}, metadata)
+	}
+	if c.options.Timeout > 0 {
+		ctx, c.cancel = context.WithTimeout(ctx, c.options.Timeout)
+	}
+	return c, ctx
+}
+
+func (c *call) callOptions() *CallOptions {
+	if c == nil {
+		return nil
+	}
+	return &c.options
+}
+
+// end releases the context of the call and fills its result, it returns the error of the call
+func (c *call) end(handlers []interface{}, err error) error {
+	if c == nil {
+		return err
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if result := c.options.Result; result != nil {
+		*result = CallResult{
+			Handlers: handlers,
+			Duration: time.Since(c.start),
+			Err:      err,
+		}
+	}
+	return err
+}
+
+// endRequest ends a Send dispatched to the handler, nil when the request was not resolved
+func (c *call) endRequest(handler *requestHandlerEntry, err error) error {
+	if c == nil || handler == nil {
+		return c.end(nil, err)
+	}
+	return c.end([]interface{}{handler.handler}, err)
+}
+
+// endNotification ends a Publish dispatched to the handlers resolved by the container
+func (c *call) endNotification(handlers []interface{}, err error) error {
+	if c == nil || c.options.Result == nil {
+		return c.end(nil, err)
+	}
+	return c.end(append([]interface{}(nil), handlers...), err)
+}
+
+// changesPipeline reports whether the options change the pipeline behaviors of a Send
+func (o *CallOptions) changesPipeline() bool {
+	return o != nil && (len(o.SkippedBehaviors) > 0 || len(o.PipelineBehaviors) > 0)
+}
+
+// pipelineBehaviors returns the behaviors of the call, from the pipeline behaviors of the container
+func (o *CallOptions) pipelineBehaviors(behaviors []ContextPipelineBehavior) []ContextPipelineBehavior {
+	callBehaviors := make([]ContextPipelineBehavior, 0, len(behaviors)+len(o.PipelineBehaviors))
+	for _, behavior := range behaviors {
+		if !o.skips(userBehavior(behavior)) {
+			callBehaviors = append(callBehaviors, behavior)
+		}
+	}
+	return append(callBehaviors, o.PipelineBehaviors...)
+}
+
+// notificationBehaviors returns the behaviors of the call, from the notification behaviors of the container
+func (o *CallOptions) notificationBehaviors(behaviors []NotificationBehavior) []NotificationBehavior {
+	if o == nil || (len(o.SkippedBehaviors) == 0 && len(o.NotificationBehaviors) == 0) {
+		return behaviors
+	}
+	callBehaviors := make([]NotificationBehavior, 0, len(behaviors)+len(o.NotificationBehaviors))
+	for _, behavior := range behaviors {
+		if !o.skips(behavior) {
+			callBehaviors = append(callBehaviors, behavior)
+		}
+	}
+	return append(callBehaviors, o.NotificationBehaviors...)
+}
+
+// notificationHandlerBehaviors returns the handler behaviors of the container which are not skipped by the call
+func (o *CallOptions) notificationHandlerBehaviors(behaviors []NotificationHandlerBehavior) []NotificationHandlerBehavior {
+	if o == nil || len(o.SkippedBehaviors) == 0 {
+		return behaviors
+	}
+	callBehaviors := make([]NotificationHandlerBehavior, 0, len(behaviors))
+	for _, behavior := range behaviors {
+		if !o.skips(behavior) {
+			callBehaviors = append(callBehaviors, behavior)
+		}
+	}
+	return callBehaviors
+}
+
+// publishStrategy returns the strategy of the call, the strategy of the container when it is not overridden
+func (o *CallOptions) publishStrategy(strategy PublishStrategy) PublishStrategy {
+	if o == nil || o.PublishStrategy == nil {
+		return strategy
+	}
+	return o.PublishStrategy
+}
+
+func (o *CallOptions) skips(behavior interface{}) bool {
+	behaviorType := reflect.TypeOf(behavior)
+	if behaviorType == nil {
+		return false
+	}
+	for _, skipped := range o.SkippedBehaviors {
+		if behaviorType == skipped || (skipped.Kind() == reflect.Interface && behaviorType.Implements(skipped)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type TestWaitingRequestHandler struct {
+}
+
+func (t TestWaitingRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+type TestMetadataRequestHandler struct {
+}
+
+func (t TestMetadataRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	tenant, _ := mediator.CallMetadata(ctx)["tenant"].(string)
+	return tenant, nil
+}
+
+type TestRejectPublishStrategy struct {
+}
+
+func (t TestRejectPublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher mediator.LaunchHandler) error {
+	return errors.New("rejected")
+}
+
+func TestCallOptions(t *testing.T) {
+	t.Run("should skip and add pipeline behaviors for the call", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"},
+			mediator.WithoutBehavior[TestPipelineBehavior]())
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+
+		response2, err := mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"},
+			mediator.WithCallPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				response, err := next(ctx, request)
+				return response.(string) + "!", err
+			})))
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline!", response2)
+
+		// The options do not outlive the call
+		response, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response)
+	})
+
+	t.Run("should bound the call with a timeout", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestWaitingRequestHandler{})),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{},
+			mediator.WithCallTimeout(10*time.Millisecond))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should attach metadata to the context of the call", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestMetadataRequestHandler{})),
+		)
+
+		ctx := context.WithValue(context.Background(), testContextKey{}, "value")
+		response, err := mediator.Send[*TestRequest, string](ctx, container, &TestRequest{},
+			mediator.WithCallMetadata("tenant", "acme"))
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", response)
+		assert.Nil(t, mediator.CallMetadata(ctx))
+	})
+
+	t.Run("should fill the detailed result of a send", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+		)
+
+		var result mediator.CallResult
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"},
+			mediator.WithCallResult(&result))
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{TestRequestHandler{}}, result.Handlers)
+		assert.NoError(t, result.Err)
+
+		_, err = mediator.Send[*TestOtherRequest, int](context.Background(), container, &TestOtherRequest{},
+			mediator.WithCallResult(&result))
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+		assert.Empty(t, result.Handlers)
+		assert.Equal(t, err, result.Err)
+	})
+
+	t.Run("should override the publish strategy for the call", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			mediator.WithPublishStrategy(TestRejectPublishStrategy{}),
+		)
+
+		err := mediator.Publish(context.Background(), container, TestNotification{Value: "test"})
+		assert.EqualError(t, err, "rejected")
+		assert.False(t, handler.Executed)
+
+		var result mediator.CallResult
+		err = mediator.NewPublisher(container).Publish(context.Background(), TestNotification{Value: "test"},
+			mediator.WithCallPublishStrategy(mediator.NewSynchronousPublishStrategy()),
+			mediator.WithCallResult(&result))
+		assert.NoError(t, err)
+		assert.True(t, handler.Executed)
+		assert.Equal(t, []interface{}{handler}, result.Handlers)
+	})
+
+	t.Run("should skip and add notification behaviors for the call", func(t *testing.T) {
+		var mu sync.Mutex
+		var calls []string
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{})),
+			mediator.WithNotificationBehavior(TestRejectNotificationBehavior{err: errors.New("rejected")}),
+			mediator.WithNotificationHandlerBehavior(TestRecordNotificationHandlerBehavior{mu: &mu, calls: &calls}),
+		)
+
+		err := mediator.Publish(context.Background(), container, TestNotification{Value: "test"},
+			mediator.WithoutBehavior[TestRejectNotificationBehavior](),
+			mediator.WithoutBehavior[TestRecordNotificationHandlerBehavior](),
+			mediator.WithCallNotificationBehavior(TestRecordNotificationBehavior{mu: &mu, calls: &calls}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"before mediator_test.TestNotification", "after mediator_test.TestNotification"}, calls)
+	})
+
+	t.Run("should skip the behaviors implementing an interface", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+		)
+
+		options := &mediator.CallOptions{}
+		mediator.WithoutBehavior[mediator.PipelineBehavior]()(options)
+		assert.Equal(t, []reflect.Type{reflect.TypeOf((*mediator.PipelineBehavior)(nil)).Elem()}, options.SkippedBehaviors)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"},
+			mediator.WithoutBehavior[mediator.PipelineBehavior]())
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+	})
+}
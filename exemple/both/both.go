@@ -84,23 +84,19 @@ func main() {
 		def1,
 		def2,
 	}
-	notificationContainer := mediator.NewPublishContainer(
-		mediator.WithNotificationDefinitionHandlers(notificationDefinitions...),
+	m := mediator.NewMediator(
+		mediator.WithPublishOptions(
+			mediator.WithNotificationDefinitionHandlers(notificationDefinitions...),
+		),
 	)
 
-	handler := NewMyRequestHandler(notificationContainer)
-	def := mediator.NewRequestHandlerDefinition[MyRequest, MyResponse](handler)
-
-	requestDefinitions := []mediator.RequestHandlerDefinition{
-		def,
-	}
-	container := mediator.NewSendContainer(
-		mediator.WithRequestDefinitionHandlers(requestDefinitions...),
-	)
+	// The mediator is a publish container, the handler can publish through it
+	handler := NewMyRequestHandler(m)
+	m.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[MyRequest, MyResponse](handler))
 
 	request := MyRequest{}
 
-	response, err := mediator.Send[MyRequest, MyResponse](externalContext, container, request)
+	response, err := mediator.Send[MyRequest, MyResponse](externalContext, m, request)
 	if err != nil {
 		// todo: handle error
 		panic(err)
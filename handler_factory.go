@@ -0,0 +1,345 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNoScope is returned when a scoped handler is dispatched with a context without scope
+var ErrNoScope = errors.New("mediator: no scope in the context")
+
+// ErrScopeClosed is returned when a scoped handler is dispatched with a closed scope
+var ErrScopeClosed = errors.New("mediator: scope is closed")
+
+// HandlerLifetime is the lifetime of the handlers created by a factory
+type HandlerLifetime int
+
+const (
+	// LifetimeSingleton creates the handler on its first dispatch and shares it with the next ones
+	LifetimeSingleton HandlerLifetime = iota
+	// LifetimeTransient creates a handler for each dispatch, it is closed when the dispatch ends
+	LifetimeTransient
+	// LifetimeScoped creates a handler once per scope, it is closed with the scope, see NewScope
+	LifetimeScoped
+)
+
+// RequestHandlerFactory creates the handler of a request for a dispatch
+type RequestHandlerFactory[TRequest Request[TResponse], TResponse interface{}] func(ctx context.Context) (RequestHandler[TRequest, TResponse], error)
+
+// NotificationHandlerFactory creates a handler of a notification for a dispatch
+type NotificationHandlerFactory[TNotification Notification] func(ctx context.Context) (NotificationHandler[TNotification], error)
+
+// NewRequestHandlerFactoryDefinition creates a request handler definition whose handlers are created by a factory
+// The handlers implementing io.Closer, or a Close method without result, are closed at the end of their lifetime.
+// The Handler method of the definition returns the factory
+func NewRequestHandlerFactoryDefinition[TRequest Request[TResponse], TResponse interface{}](factory RequestHandlerFactory[TRequest, TResponse],
+	lifetime HandlerLifetime) RequestHandlerDefinition {
+	return &FactoryRequestHandlerDefinition[TRequest, TResponse]{
+		requestType:  reflect.TypeOf((*TRequest)(nil)).Elem(),
+		responseType: reflect.TypeOf((*TResponse)(nil)).Elem(),
+		factory:      factory,
+		provider:     newHandlerProvider(factory, lifetime),
+	}
+}
+
+type FactoryRequestHandlerDefinition[TRequest Request[TResponse], TResponse interface{}] struct {
+	requestType  reflect.Type
+	responseType reflect.Type
+	factory      RequestHandlerFactory[TRequest, TResponse]
+	provider     *handlerProvider[RequestHandler[TRequest, TResponse]]
+}
+
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) RequestType() reflect.Type {
+	return f.requestType
+}
+
+// ResponseType returns the type of the responses of the handlers
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) ResponseType() reflect.Type {
+	return f.responseType
+}
+
+// Handler returns the factory of the handlers
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) Handler() interface{} {
+	return f.factory
+}
+
+// Lifetime returns the lifetime of the handlers created by the factory
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) Lifetime() HandlerLifetime {
+	return f.provider.lifetime
+}
+
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) validateHandler() error {
+	return validateFactory(f.factory)
+}
+
+func (f *FactoryRequestHandlerDefinition[TRequest, TResponse]) invokeHandler(ctx context.Context, request BaseRequest) (response interface{}, err error) {
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return nil, fmt.Errorf("request %T is not a %s", request, f.requestType)
+	}
+	handler, release, err := f.provider.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The handler is released even when it panics
+	defer func() {
+		if releaseErr := release(); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+	}()
+	return handler.Handle(ctx, typedRequest)
+}
+
+// NewNotificationHandlerFactoryDefinition creates a notification handler definition whose handlers are created by a factory
+// The handlers implementing io.Closer, or a Close method without result, are closed at the end of their lifetime.
+// The Handler method of the definition returns the factory
+func NewNotificationHandlerFactoryDefinition[TNotification Notification](factory NotificationHandlerFactory[TNotification],
+	lifetime HandlerLifetime,
+	optFns ...func(*NotificationHandlerDefinitionOptions)) NotificationHandlerDefinition {
+	options := &NotificationHandlerDefinitionOptions{}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
+
+	return &FactoryNotificationHandlerDefinition[TNotification]{
+		notificationType: reflect.TypeOf((*TNotification)(nil)).Elem(),
+		factory:          factory,
+		provider:         newHandlerProvider(factory, lifetime),
+		order:            options.Order,
+	}
+}
+
+type FactoryNotificationHandlerDefinition[TNotification Notification] struct {
+	notificationType reflect.Type
+	factory          NotificationHandlerFactory[TNotification]
+	provider         *handlerProvider[NotificationHandler[TNotification]]
+	order            int
+}
+
+func (f *FactoryNotificationHandlerDefinition[TNotification]) NotificationType() reflect.Type {
+	return f.notificationType
+}
+
+// Handler returns the factory of the handlers
+func (f *FactoryNotificationHandlerDefinition[TNotification]) Handler() interface{} {
+	return f.factory
+}
+
+func (f *FactoryNotificationHandlerDefinition[TNotification]) Order() int {
+	return f.order
+}
+
+// Lifetime returns the lifetime of the handlers created by the factory
+func (f *FactoryNotificationHandlerDefinition[TNotification]) Lifetime() HandlerLifetime {
+	return f.provider.lifetime
+}
+
+func (f *FactoryNotificationHandlerDefinition[TNotification]) validateHandler() error {
+	return validateFactory(f.factory)
+}
+
+func (f *FactoryNotificationHandlerDefinition[TNotification]) invokeHandler(ctx context.Context, notification interface{}) (err error) {
+	typedNotification, ok := notification.(TNotification)
+	if !ok {
+		return fmt.Errorf("notification %T is not a %s", notification, f.notificationType)
+	}
+	handler, release, err := f.provider.get(ctx)
+	if err != nil {
+		return err
+	}
+	// The handler is released even when it panics
+	defer func() {
+		if releaseErr := release(); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+	}()
+	return handler.Handle(ctx, typedNotification)
+}
+
+func validateFactory(factory interface{}) error {
+	if isNil(factory) {
+		return errors.New("factory is nil")
+	}
+	return nil
+}
+
+// handlerProvider provides the handlers of a factory according to their lifetime
+type handlerProvider[THandler interface{}] struct {
+	factory  func(ctx context.Context) (THandler, error)
+	lifetime HandlerLifetime
+	// singleton is the handler shared by the dispatches of the singleton lifetime
+	singleton atomic.Pointer[THandler]
+	mu        sync.Mutex
+}
+
+func newHandlerProvider[THandler interface{}, TFactory ~func(ctx context.Context) (THandler, error)](factory TFactory,
+	lifetime HandlerLifetime) *handlerProvider[THandler] {
+	return &handlerProvider[THandler]{
+		factory:  factory,
+		lifetime: lifetime,
+	}
+}
+
+func noRelease() error {
+	return nil
+}
+
+// get returns the handler of a dispatch and the function to call when the dispatch ends
+func (p *handlerProvider[THandler]) get(ctx context.Context) (THandler, func() error, error) {
+	switch p.lifetime {
+	case LifetimeTransient:
+		handler, err := p.create(ctx)
+		if err != nil {
+			return handler, nil, err
+		}
+		return handler, func() error {
+			return closeHandler(handler)
+		}, nil
+	case LifetimeScoped:
+		scope, ok := ScopeFromContext(ctx)
+		if !ok {
+			return *new(THandler), nil, ErrNoScope
+		}
+		handler, err := scope.get(p, func() (interface{}, error) {
+			return p.create(ctx)
+		})
+		if err != nil {
+			return *new(THandler), nil, err
+		}
+		return handler.(THandler), noRelease, nil
+	default:
+		if handler := p.singleton.Load(); handler != nil {
+			return *handler, noRelease, nil
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if handler := p.singleton.Load(); handler != nil {
+			return *handler, noRelease, nil
+		}
+		// A failed creation is not kept, the next dispatch tries again
+		handler, err := p.create(ctx)
+		if err != nil {
+			return handler, nil, err
+		}
+		p.singleton.Store(&handler)
+		return handler, noRelease, nil
+	}
+}
+
+func (p *handlerProvider[THandler]) create(ctx context.Context) (THandler, error) {
+	handler, err := p.factory(ctx)
+	if err != nil {
+		return handler, err
+	}
+	if interface{}(handler) == nil {
+		return handler, fmt.Errorf("%w: the factory returned a nil handler", ErrInvalidHandler)
+	}
+	return handler, nil
+}
+
+// closeHandler closes a handler implementing io.Closer or a Close method without result
+func closeHandler(handler interface{}) error {
+	switch closer := handler.(type) {
+	case io.Closer:
+		return closer.Close()
+	case interface{ Close() }:
+		closer.Close()
+	}
+	return nil
+}
+
+type scopeContextKey struct{}
+
+// Scope holds the handlers with the scoped lifetime, e.g. for the duration of an HTTP request
+// The handlers are created on their first dispatch within the scope and closed with it
+type Scope struct {
+	mu       sync.Mutex
+	handlers map[interface{}]interface{}
+	// created are the handlers in creation order, they are closed in reverse order
+	created []interface{}
+	// closeErrs are the errors of the duplicate handlers closed by the dispatches, Close reports them
+	closeErrs []error
+	closed    bool
+}
+
+// NewScope returns a context carrying a new scope, the requests and notifications dispatched with it
+// share the scoped handlers. The scope must be closed when it ends
+func NewScope(ctx context.Context) (context.Context, *Scope) {
+	scope := &Scope{
+		handlers: make(map[interface{}]interface{}),
+	}
+	return context.WithValue(ctx, scopeContextKey{}, scope), scope
+}
+
+// ScopeFromContext returns the scope carried by the context
+func ScopeFromContext(ctx context.Context) (*Scope, bool) {
+	scope, ok := ctx.Value(scopeContextKey{}).(*Scope)
+	return scope, ok
+}
+
+// get returns the handler of the key, creating it on the first call
+func (s *Scope) get(key interface{}, create func() (interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrScopeClosed
+	}
+	if handler, ok := s.handlers[key]; ok {
+		s.mu.Unlock()
+		return handler, nil
+	}
+	s.mu.Unlock()
+
+	// The factory runs without the lock, it may dispatch within the scope
+	handler, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.Join(ErrScopeClosed, closeHandler(handler))
+	}
+	if existing, ok := s.handlers[key]; ok {
+		// Another dispatch created the handler meanwhile, the dispatch uses it and the failure to close
+		// the duplicate is reported by Close
+		if err := closeHandler(handler); err != nil {
+			s.closeErrs = append(s.closeErrs, err)
+		}
+		return existing, nil
+	}
+	s.handlers[key] = handler
+	s.created = append(s.created, handler)
+	return handler, nil
+}
+
+// Close closes the handlers created within the scope, in reverse creation order
+// It also reports the errors of the duplicate handlers closed while the scope was alive.
+// The next dispatches with the scope fail with ErrScopeClosed
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	created := s.created
+	errs := s.closeErrs
+	s.handlers = nil
+	s.created = nil
+	s.closeErrs = nil
+	s.mu.Unlock()
+
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := closeHandler(created[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,249 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestClosableRequestHandler struct {
+	id     int
+	closed *[]int
+}
+
+func (t *TestClosableRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	if request.Value == "panic" {
+		panic("handler failed")
+	}
+	return request.Value, nil
+}
+
+func (t *TestClosableRequestHandler) Close() error {
+	*t.closed = append(*t.closed, t.id)
+	return nil
+}
+
+type TestFailingCloseRequestHandler struct {
+	TestRequestHandler
+}
+
+func (t TestFailingCloseRequestHandler) Close() error {
+	return errors.New("close failed")
+}
+
+type TestClosableNotificationHandler struct {
+	handled int
+	closed  bool
+}
+
+func (t *TestClosableNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	t.handled++
+	return nil
+}
+
+func (t *TestClosableNotificationHandler) Close() {
+	t.closed = true
+}
+
+// newTestRequestHandlerFactory returns a factory counting the handlers it creates
+func newTestRequestHandlerFactory(created *int, closed *[]int) mediator.RequestHandlerFactory[*TestRequest, string] {
+	return func(ctx context.Context) (mediator.RequestHandler[*TestRequest, string], error) {
+		*created++
+		return &TestClosableRequestHandler{id: *created, closed: closed}, nil
+	}
+}
+
+func TestHandlerFactory(t *testing.T) {
+	t.Run("should create and close a transient handler for each dispatch", func(t *testing.T) {
+		created := 0
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				newTestRequestHandlerFactory(&created, &closed), mediator.LifetimeTransient)),
+		)
+
+		for i := 0; i < 2; i++ {
+			response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+			assert.NoError(t, err)
+			assert.Equal(t, "test", response)
+		}
+		assert.Equal(t, 2, created)
+		assert.Equal(t, []int{1, 2}, closed)
+	})
+
+	t.Run("should close a transient handler which panics", func(t *testing.T) {
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				func(ctx context.Context) (mediator.RequestHandler[*TestRequest, string], error) {
+					return &TestClosableRequestHandler{id: 1, closed: &closed}, nil
+				}, mediator.LifetimeTransient)),
+			mediator.WithContextPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (response interface{}, err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("recovered: %v", r)
+					}
+				}()
+				return next(ctx, request)
+			})),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "panic"})
+		assert.ErrorContains(t, err, "recovered")
+		assert.Equal(t, []int{1}, closed)
+	})
+
+	t.Run("should create a singleton handler once", func(t *testing.T) {
+		created := 0
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				newTestRequestHandlerFactory(&created, &closed), mediator.LifetimeSingleton)),
+		)
+
+		for i := 0; i < 2; i++ {
+			_, err := mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, created)
+		assert.Empty(t, closed)
+	})
+
+	t.Run("should retry a singleton handler whose creation failed", func(t *testing.T) {
+		calls := 0
+		factory := func(ctx context.Context) (mediator.RequestHandler[*TestRequest, string], error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return TestRequestHandler{}, nil
+		}
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition[*TestRequest, string](factory, mediator.LifetimeSingleton)),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorContains(t, err, "unavailable")
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+	})
+
+	t.Run("should share a scoped handler within a scope and close it with the scope", func(t *testing.T) {
+		created := 0
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				newTestRequestHandlerFactory(&created, &closed), mediator.LifetimeScoped)),
+		)
+
+		ctx, scope := mediator.NewScope(context.Background())
+		for i := 0; i < 2; i++ {
+			_, err := mediator.Send[*TestRequest, string](ctx, container, &TestRequest{Value: "test"})
+			assert.NoError(t, err)
+		}
+		otherCtx, otherScope := mediator.NewScope(context.Background())
+		_, err := mediator.Send[*TestRequest, string](otherCtx, container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, created)
+		assert.Empty(t, closed)
+
+		assert.NoError(t, scope.Close())
+		assert.NoError(t, otherScope.Close())
+		assert.Equal(t, []int{1, 2}, closed)
+
+		_, err = mediator.Send[*TestRequest, string](ctx, container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrScopeClosed)
+	})
+
+	t.Run("should use the scoped handler created meanwhile when the duplicate fails to close", func(t *testing.T) {
+		var container mediator.SendContainer
+		calls := 0
+		factory := func(ctx context.Context) (mediator.RequestHandler[*TestRequest, string], error) {
+			calls++
+			if calls == 1 {
+				// The nested dispatch creates the scoped handler before this one is created
+				if _, err := mediator.Send[*TestRequest, string](ctx, container, &TestRequest{Value: "nested"}); err != nil {
+					return nil, err
+				}
+				return TestFailingCloseRequestHandler{}, nil
+			}
+			return TestRequestHandler{}, nil
+		}
+		container = mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition[*TestRequest, string](factory, mediator.LifetimeScoped)),
+		)
+
+		ctx, scope := mediator.NewScope(context.Background())
+		response, err := mediator.Send[*TestRequest, string](ctx, container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+		assert.Equal(t, 2, calls)
+		assert.EqualError(t, scope.Close(), "close failed")
+	})
+
+	t.Run("should fail to dispatch a scoped handler without scope", func(t *testing.T) {
+		created := 0
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				newTestRequestHandlerFactory(&created, &closed), mediator.LifetimeScoped)),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoScope)
+		var dispatchErr *mediator.DispatchError
+		assert.ErrorAs(t, err, &dispatchErr)
+		assert.Equal(t, mediator.StageHandler, dispatchErr.Stage)
+		assert.Equal(t, 0, created)
+	})
+
+	t.Run("should fail when the factory returns a nil handler", func(t *testing.T) {
+		factory := func(ctx context.Context) (mediator.RequestHandler[*TestRequest, string], error) {
+			return nil, nil
+		}
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition[*TestRequest, string](factory, mediator.LifetimeTransient)),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+	})
+
+	t.Run("should create the notification handlers with a factory", func(t *testing.T) {
+		var handlers []*TestClosableNotificationHandler
+		factory := func(ctx context.Context) (mediator.NotificationHandler[TestNotification], error) {
+			handler := &TestClosableNotificationHandler{}
+			handlers = append(handlers, handler)
+			return handler, nil
+		}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerFactoryDefinition[TestNotification](factory, mediator.LifetimeTransient)),
+		)
+
+		err := mediator.Publish(context.Background(), container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Len(t, handlers, 1)
+		assert.Equal(t, 1, handlers[0].handled)
+		assert.True(t, handlers[0].closed)
+	})
+
+	t.Run("should validate the factory definitions", func(t *testing.T) {
+		created := 0
+		var closed []int
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerFactoryDefinition(
+				newTestRequestHandlerFactory(&created, &closed), mediator.LifetimeTransient)),
+		)
+		assert.NoError(t, container.Validate())
+
+		publishContainer := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerFactoryDefinition[TestNotification](nil, mediator.LifetimeTransient)),
+		)
+		assert.ErrorIs(t, publishContainer.Validate(), mediator.ErrInvalidHandler)
+		assert.Equal(t, 0, created)
+	})
+}
@@ -0,0 +1,179 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// handleMethodPrefix is the prefix of the handler methods discovered by RegisterHandlersOf
+const handleMethodPrefix = "Handle"
+
+var baseRequestType = reflect.TypeOf((*BaseRequest)(nil)).Elem()
+
+// HandlerDefinitions are the request and notification handler definitions of the methods of an object
+type HandlerDefinitions struct {
+	Requests      []RequestHandlerDefinition
+	Notifications []NotificationHandlerDefinition
+}
+
+type HandlersOfOptions struct {
+	// Methods are the names of the handler methods, when empty the methods whose name starts with Handle are used
+	Methods []string
+}
+
+// WithHandlerMethods sets the names of the handler methods instead of discovering them by their Handle prefix
+func WithHandlerMethods(methods ...string) func(*HandlersOfOptions) {
+	return func(options *HandlersOfOptions) {
+		options.Methods = append(options.Methods, methods...)
+	}
+}
+
+// RegisterHandlersOf creates the handler definitions of the methods of an object handling several messages,
+// e.g. a projection with HandleUserCreated and HandleUserRenamed methods
+// A method accepting a context and a request, and returning a response and an error, handles the request.
+// A method accepting a context and a notification, and returning an error, handles the notification.
+// The methods are discovered by their Handle prefix, in name order, unless they are listed with WithHandlerMethods.
+// The discovered methods which are not handlers are ignored, the listed ones fail with ErrInvalidHandler.
+// The handlers are called with reflection
+func RegisterHandlersOf(handler interface{}, optFns ...func(*HandlersOfOptions)) (HandlerDefinitions, error) {
+	options := &HandlersOfOptions{}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
+
+	var definitions HandlerDefinitions
+	if isNil(handler) {
+		return definitions, fmt.Errorf("%w: handler is nil", ErrInvalidHandler)
+	}
+	handlerValue := reflect.ValueOf(handler)
+	handlerType := handlerValue.Type()
+
+	methods := options.Methods
+	// The discovered methods which are not handlers, e.g. a Handled() bool accessor, are skipped
+	// while the methods listed with WithHandlerMethods must be handlers
+	discovered := len(methods) == 0
+	if discovered {
+		for i := 0; i < handlerType.NumMethod(); i++ {
+			if name := handlerType.Method(i).Name; strings.HasPrefix(name, handleMethodPrefix) {
+				methods = append(methods, name)
+			}
+		}
+	}
+
+	requestTypes := make(map[reflect.Type]string, len(methods))
+	for _, name := range methods {
+		method, ok := handlerType.MethodByName(name)
+		if !ok {
+			return HandlerDefinitions{}, fmt.Errorf("%w: %s has no %s method", ErrInvalidHandler, handlerType, name)
+		}
+		methodType := method.Type
+		// The first input of the method type is the receiver
+		if methodType.NumIn() != 3 || methodType.In(1) != contextType {
+			if discovered {
+				continue
+			}
+			return HandlerDefinitions{}, fmt.Errorf("%w: %s.%s does not accept a context and a message", ErrInvalidHandler, handlerType, name)
+		}
+		messageType := methodType.In(2)
+		methodValue := handlerValue.Method(method.Index)
+
+		switch {
+		case methodType.NumOut() == 1 && methodType.Out(0) == errorType:
+			definitions.Notifications = append(definitions.Notifications, &methodNotificationHandlerDefinition{
+				notificationType: messageType,
+				handler:          handler,
+				method:           methodValue,
+			})
+		case methodType.NumOut() == 2 && methodType.Out(1) == errorType && messageType.Implements(baseRequestType):
+			if previous, ok := requestTypes[messageType]; ok {
+				return HandlerDefinitions{}, fmt.Errorf("%w for request %s: %s.%s and %s.%s", ErrDuplicateHandler,
+					messageType, handlerType, previous, handlerType, name)
+			}
+			requestTypes[messageType] = name
+			definitions.Requests = append(definitions.Requests, &methodRequestHandlerDefinition{
+				requestType:  messageType,
+				responseType: methodType.Out(0),
+				handler:      handler,
+				method:       methodValue,
+			})
+		default:
+			if discovered {
+				continue
+			}
+			return HandlerDefinitions{}, fmt.Errorf("%w: %s.%s does not handle a request or a notification", ErrInvalidHandler, handlerType, name)
+		}
+	}
+	if discovered && len(definitions.Requests) == 0 && len(definitions.Notifications) == 0 {
+		return definitions, fmt.Errorf("%w: %s has no handler method starting with %s", ErrInvalidHandler, handlerType, handleMethodPrefix)
+	}
+	return definitions, nil
+}
+
+// methodRequestHandlerDefinition is a request handler definition calling a method of its handler
+type methodRequestHandlerDefinition struct {
+	requestType  reflect.Type
+	responseType reflect.Type
+	handler      interface{}
+	method       reflect.Value
+}
+
+func (m *methodRequestHandlerDefinition) RequestType() reflect.Type {
+	return m.requestType
+}
+
+func (m *methodRequestHandlerDefinition) ResponseType() reflect.Type {
+	return m.responseType
+}
+
+func (m *methodRequestHandlerDefinition) Handler() interface{} {
+	return m.handler
+}
+
+// validateHandler has nothing to check, the method was checked when the definition was created
+func (m *methodRequestHandlerDefinition) validateHandler() error {
+	return nil
+}
+
+func (m *methodRequestHandlerDefinition) invokeHandler(ctx context.Context, request BaseRequest) (interface{}, error) {
+	if request == nil || !reflect.TypeOf(request).AssignableTo(m.requestType) {
+		return nil, fmt.Errorf("request %T is not a %s", request, m.requestType)
+	}
+	result := m.method.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(request)})
+	if err := result[1].Interface(); err != nil {
+		return result[0].Interface(), err.(error)
+	}
+	return result[0].Interface(), nil
+}
+
+// methodNotificationHandlerDefinition is a notification handler definition calling a method of its handler
+type methodNotificationHandlerDefinition struct {
+	notificationType reflect.Type
+	handler          interface{}
+	method           reflect.Value
+}
+
+func (m *methodNotificationHandlerDefinition) NotificationType() reflect.Type {
+	return m.notificationType
+}
+
+func (m *methodNotificationHandlerDefinition) Handler() interface{} {
+	return m.handler
+}
+
+// validateHandler has nothing to check, the method was checked when the definition was created
+func (m *methodNotificationHandlerDefinition) validateHandler() error {
+	return nil
+}
+
+func (m *methodNotificationHandlerDefinition) invokeHandler(ctx context.Context, notification interface{}) error {
+	if notification == nil || !reflect.TypeOf(notification).AssignableTo(m.notificationType) {
+		return fmt.Errorf("notification %T is not a %s", notification, m.notificationType)
+	}
+	result := m.method.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(notification)})
+	if err := result[0].Interface(); err != nil {
+		return err.(error)
+	}
+	return nil
+}
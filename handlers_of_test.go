@@ -0,0 +1,218 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TestUserRenamed struct {
+	Name string
+}
+
+type TestUserProjection struct {
+	names []string
+}
+
+func (t *TestUserProjection) HandleUserCreated(ctx context.Context, notification TestUserCreated) error {
+	t.names = append(t.names, notification.ID)
+	return nil
+}
+
+func (t *TestUserProjection) HandleUserRenamed(ctx context.Context, notification TestUserRenamed) error {
+	if notification.Name == "" {
+		return errors.New("name is required")
+	}
+	t.names[len(t.names)-1] = notification.Name
+	return nil
+}
+
+func (t *TestUserProjection) HandleRequest(ctx context.Context, request *TestRequest) (string, error) {
+	return t.names[len(t.names)-1], nil
+}
+
+func (t *TestUserProjection) OnUserCreated(ctx context.Context, notification TestUserCreated) error {
+	return t.HandleUserCreated(ctx, notification)
+}
+
+func (t *TestUserProjection) Handled() bool {
+	return len(t.names) > 0
+}
+
+func (t *TestUserProjection) Names() []string {
+	return t.names
+}
+
+type TestEventLog struct {
+	events []string
+}
+
+func (t *TestEventLog) HandleDomainEvent(ctx context.Context, event TestDomainEvent) error {
+	t.events = append(t.events, "event:"+event.AggregateID())
+	return nil
+}
+
+func (t *TestEventLog) HandleUserCreated(ctx context.Context, notification TestUserCreated) error {
+	t.events = append(t.events, "created:"+notification.ID)
+	return nil
+}
+
+// TestFlakyProjection fails to handle its first user created notifications with a retryable error
+type TestFlakyProjection struct {
+	failures int
+	created  int
+	events   int
+}
+
+func (t *TestFlakyProjection) HandleUserCreated(ctx context.Context, notification TestUserCreated) error {
+	t.created++
+	if t.created <= t.failures {
+		return TestTransientError{}
+	}
+	return nil
+}
+
+func (t *TestFlakyProjection) HandleDomainEvent(ctx context.Context, event TestDomainEvent) error {
+	t.events++
+	return nil
+}
+
+type TestInvalidProjection struct {
+}
+
+func (t TestInvalidProjection) HandleUserCreated(notification TestUserCreated) error {
+	return nil
+}
+
+type TestDuplicateProjection struct {
+}
+
+func (t TestDuplicateProjection) HandleFirst(ctx context.Context, request *TestRequest) (string, error) {
+	return "first", nil
+}
+
+func (t TestDuplicateProjection) HandleSecond(ctx context.Context, request *TestRequest) (string, error) {
+	return "second", nil
+}
+
+func TestRegisterHandlersOf(t *testing.T) {
+	t.Run("should register the handler methods of an object", func(t *testing.T) {
+		projection := &TestUserProjection{}
+		definitions, err := mediator.RegisterHandlersOf(projection)
+		assert.NoError(t, err)
+		assert.Len(t, definitions.Requests, 1)
+		assert.Len(t, definitions.Notifications, 2)
+
+		m := mediator.NewMediator(
+			mediator.WithSendOptions(mediator.WithRequestDefinitionHandlers(definitions.Requests...)),
+			mediator.WithPublishOptions(mediator.WithNotificationDefinitionHandlers(definitions.Notifications...)),
+		)
+		assert.NoError(t, m.Validate())
+
+		assert.NoError(t, m.Publish(context.Background(), TestUserCreated{ID: "John"}))
+		assert.NoError(t, m.Publish(context.Background(), TestUserRenamed{Name: "Jane"}))
+		assert.Equal(t, []string{"Jane"}, projection.names)
+
+		err = m.Publish(context.Background(), TestUserRenamed{})
+		assert.ErrorContains(t, err, "name is required")
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), m, &TestRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", response)
+	})
+
+	t.Run("should fail for a nil request given by a behavior", func(t *testing.T) {
+		definitions, err := mediator.RegisterHandlersOf(&TestUserProjection{names: []string{"John"}})
+		assert.NoError(t, err)
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandlers(definitions.Requests...),
+			mediator.WithContextPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				return next(ctx, nil)
+			})),
+		)
+
+		_, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.ErrorContains(t, err, "request <nil> is not a *mediator_test.TestRequest")
+	})
+
+	t.Run("should register the listed methods", func(t *testing.T) {
+		projection := &TestUserProjection{}
+		definitions, err := mediator.RegisterHandlersOf(projection, mediator.WithHandlerMethods("OnUserCreated"))
+		assert.NoError(t, err)
+		assert.Empty(t, definitions.Requests)
+		assert.Len(t, definitions.Notifications, 1)
+
+		container := mediator.NewPublishContainer(mediator.WithNotificationDefinitionHandlers(definitions.Notifications...))
+		assert.NoError(t, mediator.Publish(context.Background(), container, TestUserCreated{ID: "John"}))
+		assert.Equal(t, []string{"John"}, projection.names)
+	})
+
+	t.Run("should publish to each method of an object matching the notification", func(t *testing.T) {
+		eventLog := &TestEventLog{}
+		definitions, err := mediator.RegisterHandlersOf(eventLog)
+		assert.NoError(t, err)
+
+		container := mediator.NewPublishContainer(mediator.WithNotificationDefinitionHandlers(definitions.Notifications...))
+		assert.NoError(t, mediator.Publish(context.Background(), container, TestUserCreated{ID: "1"}))
+		assert.ElementsMatch(t, []string{"event:1", "created:1"}, eventLog.events)
+	})
+
+	t.Run("should retry the failed method of an object matching the notification", func(t *testing.T) {
+		projection := &TestFlakyProjection{failures: 1}
+		definitions, err := mediator.RegisterHandlersOf(projection)
+		assert.NoError(t, err)
+
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(definitions.Notifications...),
+			mediator.WithNotificationHandlerBehavior(mediator.NewRetryNotificationHandlerBehavior(mediator.WithRetryBackoff(time.Millisecond, time.Millisecond))),
+		)
+		assert.NoError(t, mediator.Publish(context.Background(), container, TestUserCreated{ID: "1"}))
+		assert.Equal(t, 2, projection.created)
+		assert.Equal(t, 1, projection.events)
+	})
+
+	t.Run("should skip the discovered methods which are not handlers", func(t *testing.T) {
+		projection := &TestUserProjection{}
+		assert.False(t, projection.Handled())
+
+		definitions, err := mediator.RegisterHandlersOf(projection)
+		assert.NoError(t, err)
+		assert.Len(t, definitions.Requests, 1)
+		assert.Len(t, definitions.Notifications, 2)
+
+		container := mediator.NewPublishContainer(mediator.WithNotificationDefinitionHandlers(definitions.Notifications...))
+		assert.NoError(t, mediator.Publish(context.Background(), container, TestUserCreated{ID: "1"}))
+		assert.True(t, projection.Handled())
+	})
+
+	t.Run("should fail for the methods which are not handlers", func(t *testing.T) {
+		_, err := mediator.RegisterHandlersOf(&TestUserProjection{}, mediator.WithHandlerMethods("Names"))
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(&TestUserProjection{}, mediator.WithHandlerMethods("Unknown"))
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(&TestUserProjection{}, mediator.WithHandlerMethods("Handled"))
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(TestInvalidProjection{}, mediator.WithHandlerMethods("HandleUserCreated"))
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(TestInvalidProjection{})
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(TestUserCreated{})
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+
+		_, err = mediator.RegisterHandlersOf(nil)
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+	})
+
+	t.Run("should fail for a request handled by several methods", func(t *testing.T) {
+		_, err := mediator.RegisterHandlersOf(TestDuplicateProjection{})
+		assert.ErrorIs(t, err, mediator.ErrDuplicateHandler)
+	})
+}
@@ -0,0 +1,75 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// Mediator is the single entry point for requests and notifications
+// It is both a SendContainer and a PublishContainer, so it can be given to Send, Stream and Publish,
+// and to the code expecting either container, and it is both a Sender and a Publisher
+type Mediator interface {
+	SendContainer
+	PublishContainer
+	Sender
+	Publisher
+}
+
+// MediatorOptions configures a Mediator with the options of its send and publish containers
+type MediatorOptions struct {
+	SendContainerOptions
+	PublishOptions
+}
+
+// WithSendOptions applies send container options to a Mediator
+func WithSendOptions(optFns ...func(*SendContainerOptions)) func(*MediatorOptions) {
+	return func(options *MediatorOptions) {
+		for _, optFn := range optFns {
+			optFn(&options.SendContainerOptions)
+		}
+	}
+}
+
+// WithPublishOptions applies publish container options to a Mediator
+func WithPublishOptions(optFns ...func(*PublishOptions)) func(*MediatorOptions) {
+	return func(options *MediatorOptions) {
+		for _, optFn := range optFns {
+			optFn(&options.PublishOptions)
+		}
+	}
+}
+
+type defaultMediator struct {
+	*sendContainer
+	*notificationContainer
+}
+
+// NewMediator creates a Mediator holding the request and notification handlers
+func NewMediator(optFns ...func(*MediatorOptions)) Mediator {
+	options := &MediatorOptions{}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
+	return &defaultMediator{
+		sendContainer:         newSendContainer(&options.SendContainerOptions),
+		notificationContainer: newNotificationContainer(&options.PublishOptions),
+	}
+}
+
+// Validate reports the misconfigurations of the request and notification handlers
+func (m *defaultMediator) Validate() error {
+	return errors.Join(m.sendContainer.Validate(), m.notificationContainer.Validate())
+}
+
+func (m *defaultMediator) Send(ctx context.Context, request BaseRequest, optFns ...func(*CallOptions)) (interface{}, error) {
+	return sender{container: m.sendContainer}.Send(ctx, request, optFns...)
+}
+
+func (m *defaultMediator) Stream(ctx context.Context, request BaseRequest) (iter.Seq2[interface{}, error], error) {
+	return sender{container: m.sendContainer}.Stream(ctx, request)
+}
+
+func (m *defaultMediator) Publish(ctx context.Context, notification interface{}, optFns ...func(*CallOptions)) error {
+	return publisher{container: m.notificationContainer}.Publish(ctx, notification, optFns...)
+}
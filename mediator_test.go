@@ -0,0 +1,106 @@
+package mediator_test
+
+import (
+	"context"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestPublishingRequestHandler struct {
+	publisher mediator.Publisher
+}
+
+func (t TestPublishingRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	if err := t.publisher.Publish(ctx, TestNotification{Value: request.Value}); err != nil {
+		return "", err
+	}
+	return request.Value, nil
+}
+
+func TestMediator(t *testing.T) {
+	t.Run("should send requests and publish notifications", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		m := mediator.NewMediator(
+			mediator.WithSendOptions(
+				mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+				mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+			),
+			mediator.WithPublishOptions(
+				mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), m, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response)
+
+		response2, err := m.Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response2)
+
+		err = mediator.Publish(context.Background(), m, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.True(t, handler.Executed)
+
+		handler.Executed = false
+		err = m.Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.True(t, handler.Executed)
+	})
+
+	t.Run("should stream requests", func(t *testing.T) {
+		m := mediator.NewMediator(
+			mediator.WithSendOptions(
+				mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+			),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), m, TestStreamRequest{Count: 2})
+		assert.NoError(t, err)
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, items)
+
+		untyped, err := m.Stream(context.Background(), TestStreamRequest{Count: 2})
+		assert.NoError(t, err)
+		untypedItems, err := collectStream(untyped)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1}, untypedItems)
+	})
+
+	t.Run("should be given to the code expecting a container", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		m := mediator.NewMediator(
+			mediator.WithPublishOptions(
+				mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			),
+		)
+		m.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestPublishingRequestHandler{publisher: m}))
+
+		var sendContainer mediator.SendContainer = m
+		var publishContainer mediator.PublishContainer = m
+		response, err := mediator.NewSender(sendContainer).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+		assert.True(t, handler.Executed)
+
+		err = mediator.NewPublisher(publishContainer).Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should validate the request and notification handlers", func(t *testing.T) {
+		m := mediator.NewMediator(
+			mediator.WithSendOptions(
+				mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](nil)),
+			),
+			mediator.WithPublishOptions(
+				mediator.WithRequiredNotification[TestNotification](),
+			),
+		)
+
+		err := m.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+}
@@ -12,3 +12,11 @@ type Notification interface {
 type NotificationHandler[TNotification Notification] interface {
 	Handle(ctx context.Context, notification TNotification) error
 }
+
+// NotificationHandlerFunc is a function handling a notification, it is a NotificationHandler
+// It lets a function be given to NewNotificationHandlerDefinition without declaring a handler type
+type NotificationHandlerFunc[TNotification Notification] func(ctx context.Context, notification TNotification) error
+
+func (f NotificationHandlerFunc[TNotification]) Handle(ctx context.Context, notification TNotification) error {
+	return f(ctx, notification)
+}
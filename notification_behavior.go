@@ -0,0 +1,20 @@
+package mediator
+
+import "context"
+
+// NotificationBehavior is a marker interface for notification behaviors
+// A notification behavior wraps the whole publication of a notification to all its handlers
+type NotificationBehavior interface {
+	Handle(ctx context.Context, notification Notification, next NotificationNextFunc) error
+}
+
+// NotificationHandlerBehavior is a marker interface for notification handler behaviors
+// A notification handler behavior wraps each invocation of a notification handler,
+// whatever the publish strategy is
+type NotificationHandlerBehavior interface {
+	Handle(ctx context.Context, notification Notification, handler interface{}, next NotificationNextFunc) error
+}
+
+// NotificationNextFunc is a function that executes the next step of a notification pipeline
+// The context given to it is forwarded down to the handlers
+type NotificationNextFunc func(ctx context.Context) error
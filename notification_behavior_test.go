@@ -0,0 +1,132 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+type TestPanicNotificationHandler struct {
+}
+
+func (h *TestPanicNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	panic("boom")
+}
+
+type TestRecordNotificationBehavior struct {
+	mu    *sync.Mutex
+	calls *[]string
+}
+
+func (t TestRecordNotificationBehavior) Handle(ctx context.Context, notification mediator.Notification, next mediator.NotificationNextFunc) error {
+	t.record(fmt.Sprintf("before %T", notification))
+	err := next(ctx)
+	t.record(fmt.Sprintf("after %T", notification))
+	return err
+}
+
+func (t TestRecordNotificationBehavior) record(call string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*t.calls = append(*t.calls, call)
+}
+
+type TestRecordNotificationHandlerBehavior struct {
+	mu    *sync.Mutex
+	calls *[]string
+}
+
+func (t TestRecordNotificationHandlerBehavior) Handle(ctx context.Context, notification mediator.Notification, handler interface{}, next mediator.NotificationNextFunc) error {
+	t.mu.Lock()
+	*t.calls = append(*t.calls, fmt.Sprintf("%T", handler))
+	t.mu.Unlock()
+	return next(ctx)
+}
+
+type TestRecoverNotificationHandlerBehavior struct {
+}
+
+func (t TestRecoverNotificationHandlerBehavior) Handle(ctx context.Context, notification mediator.Notification, handler interface{}, next mediator.NotificationNextFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler %T panicked: %v", handler, r)
+		}
+	}()
+	return next(ctx)
+}
+
+func TestNotificationBehavior(t *testing.T) {
+	strategies := map[string]mediator.PublishStrategy{
+		"synchronous": mediator.NewSynchronousPublishStrategy(),
+		"parallel":    mediator.NewParallelPublishStrategy(),
+	}
+
+	for name, strategy := range strategies {
+		t.Run(name+" should wrap the publication and each handler", func(t *testing.T) {
+			var mu sync.Mutex
+			var calls []string
+			handler := &TestNotificationHandler{}
+			handler2 := &TestNotificationHandler2{}
+			container := mediator.NewPublishContainer(
+				mediator.WithNotificationDefinitionHandlers(
+					mediator.NewNotificationHandlerDefinition[TestNotification](handler),
+					mediator.NewNotificationHandlerDefinition[TestNotification](handler2),
+				),
+				mediator.WithPublishStrategy(strategy),
+				mediator.WithNotificationBehavior(TestRecordNotificationBehavior{mu: &mu, calls: &calls}),
+				mediator.WithNotificationHandlerBehavior(TestRecordNotificationHandlerBehavior{mu: &mu, calls: &calls}),
+			)
+
+			err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+			assert.NoError(t, err)
+
+			assert.True(t, handler.Executed)
+			assert.True(t, handler2.Executed)
+			assert.Len(t, calls, 4)
+			assert.Equal(t, "before mediator_test.TestNotification", calls[0])
+			assert.ElementsMatch(t, []string{"*mediator_test.TestNotificationHandler", "*mediator_test.TestNotificationHandler2"}, calls[1:3])
+			assert.Equal(t, "after mediator_test.TestNotification", calls[3])
+		})
+
+		t.Run(name+" should recover a panicking handler through the publisher", func(t *testing.T) {
+			handler := &TestNotificationHandler{}
+			container := mediator.NewPublishContainer(
+				mediator.WithNotificationDefinitionHandlers(
+					mediator.NewNotificationHandlerDefinition[TestNotification](&TestPanicNotificationHandler{}),
+					mediator.NewNotificationHandlerDefinition[TestNotification](handler),
+				),
+				mediator.WithPublishStrategy(strategy),
+				mediator.WithNotificationHandlerBehavior(TestRecoverNotificationHandlerBehavior{}),
+			)
+			publisher := mediator.NewPublisher(container)
+
+			err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+			assert.ErrorContains(t, err, "handler *mediator_test.TestPanicNotificationHandler panicked: boom")
+		})
+	}
+
+	t.Run("should return the error of a notification behavior", func(t *testing.T) {
+		behaviorErr := errors.New("rejected")
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			mediator.WithNotificationBehavior(TestRejectNotificationBehavior{err: behaviorErr}),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.ErrorIs(t, err, behaviorErr)
+		assert.False(t, handler.Executed)
+	})
+}
+
+type TestRejectNotificationBehavior struct {
+	err error
+}
+
+func (t TestRejectNotificationBehavior) Handle(ctx context.Context, notification mediator.Notification, next mediator.NotificationNextFunc) error {
+	return t.err
+}
@@ -1,6 +1,10 @@
 package mediator
 
-import "reflect"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
 
 // NotificationHandlerDefinition is a definition of a notification handler
 // It is used to define a notification handler and its associated notification type
@@ -9,20 +13,47 @@ type NotificationHandlerDefinition interface {
 	Handler() interface{}
 }
 
+// OrderedNotificationHandlerDefinition is a notification handler definition with an order
+// The handlers of a notification are executed by ascending order, then by registration order
+type OrderedNotificationHandlerDefinition interface {
+	NotificationHandlerDefinition
+	Order() int
+}
+
+type NotificationHandlerDefinitionOptions struct {
+	Order int
+}
+
+// WithHandlerOrder sets the order of the notification handler, lower orders are executed first
+// The handlers without order have the order 0
+func WithHandlerOrder(order int) func(*NotificationHandlerDefinitionOptions) {
+	return func(options *NotificationHandlerDefinitionOptions) {
+		options.Order = order
+	}
+}
+
 // NewNotificationHandlerDefinition creates a new notification handler definition
-func NewNotificationHandlerDefinition[TNotification Notification](handler NotificationHandler[TNotification]) NotificationHandlerDefinition {
-	var notification TNotification
-	notificationType := reflect.TypeOf(notification)
+func NewNotificationHandlerDefinition[TNotification Notification](handler NotificationHandler[TNotification],
+	optFns ...func(*NotificationHandlerDefinitionOptions)) NotificationHandlerDefinition {
+	// TypeOf a pointer keeps the interface types, which have no dynamic type
+	notificationType := reflect.TypeOf((*TNotification)(nil)).Elem()
+
+	options := &NotificationHandlerDefinitionOptions{}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
 
 	return TypedNotificationHandlerDefinition[TNotification]{
 		notificationType: notificationType,
 		handler:          handler,
+		order:            options.Order,
 	}
 }
 
 type TypedNotificationHandlerDefinition[TNotification Notification] struct {
 	notificationType reflect.Type
 	handler          NotificationHandler[TNotification]
+	order            int
 }
 
 func (t TypedNotificationHandlerDefinition[TNotification]) NotificationType() reflect.Type {
@@ -32,3 +63,28 @@ func (t TypedNotificationHandlerDefinition[TNotification]) NotificationType() re
 func (t TypedNotificationHandlerDefinition[TNotification]) Handler() interface{} {
 	return t.handler
 }
+
+func (t TypedNotificationHandlerDefinition[TNotification]) Order() int {
+	return t.order
+}
+
+// notificationHandlerOrder returns the order of a notification handler definition, 0 if it has none
+func notificationHandlerOrder(definition NotificationHandlerDefinition) int {
+	if ordered, ok := definition.(OrderedNotificationHandlerDefinition); ok {
+		return ordered.Order()
+	}
+	return 0
+}
+
+func (t TypedNotificationHandlerDefinition[TNotification]) invokeHandler(ctx context.Context, notification interface{}) error {
+	typedNotification, ok := notification.(TNotification)
+	if !ok {
+		return fmt.Errorf("notification %T is not a %s", notification, t.notificationType)
+	}
+	return t.handler.Handle(ctx, typedNotification)
+}
+
+// notificationHandlerInvoker is implemented by the definitions calling their handler without reflection
+type notificationHandlerInvoker interface {
+	invokeHandler(ctx context.Context, notification interface{}) error
+}
@@ -4,6 +4,55 @@ import "context"
 
 // PipelineBehavior is a marker interface for pipeline behaviors
 // A pipeline behavior is a behavior that is executed as part of a pipeline
+// Its next step always runs with the context and request given to the pipeline,
+// use a ContextPipelineBehavior to forward a derived context or another request
 type PipelineBehavior interface {
 	Handle(ctx context.Context, request BaseRequest, next RequestHandlerFunc) (interface{}, error)
 }
+
+// ContextPipelineBehavior is a marker interface for context-propagating pipeline behaviors
+// A context pipeline behavior is a behavior that is executed as part of a pipeline and
+// passes the context and the request of its choice to the next step of the pipeline
+type ContextPipelineBehavior interface {
+	Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error)
+}
+
+// NextFunc is a function that executes the next step of a pipeline
+// The context and request given to it are forwarded down to the request handler
+type NextFunc func(ctx context.Context, request BaseRequest) (interface{}, error)
+
+// PipelineBehaviorFunc is a function executed as part of a pipeline, it is a PipelineBehavior
+type PipelineBehaviorFunc func(ctx context.Context, request BaseRequest, next RequestHandlerFunc) (interface{}, error)
+
+func (f PipelineBehaviorFunc) Handle(ctx context.Context, request BaseRequest, next RequestHandlerFunc) (interface{}, error) {
+	return f(ctx, request, next)
+}
+
+// ContextPipelineBehaviorFunc is a function executed as part of a pipeline, it is a ContextPipelineBehavior
+type ContextPipelineBehaviorFunc func(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error)
+
+func (f ContextPipelineBehaviorFunc) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	return f(ctx, request, next)
+}
+
+// AdaptPipelineBehavior converts a PipelineBehavior to a ContextPipelineBehavior
+// The next step of the adapted behavior runs with the context and request it received
+func AdaptPipelineBehavior(pipelineBehavior PipelineBehavior) ContextPipelineBehavior {
+	return pipelineBehaviorAdapter{
+		pipelineBehavior: pipelineBehavior,
+	}
+}
+
+type pipelineBehaviorAdapter struct {
+	pipelineBehavior PipelineBehavior
+}
+
+func (p pipelineBehaviorAdapter) unwrap() interface{} {
+	return p.pipelineBehavior
+}
+
+func (p pipelineBehaviorAdapter) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	return p.pipelineBehavior.Handle(ctx, request, func() (interface{}, error) {
+		return next(ctx, request)
+	})
+}
@@ -0,0 +1,71 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedPipelineBehavior is a marker interface for typed pipeline behaviors
+// A typed pipeline behavior is a pipeline behavior bound to a request type and its response type,
+// it is only executed for the requests of this type
+type TypedPipelineBehavior[TRequest Request[TResponse], TResponse interface{}] interface {
+	Handle(ctx context.Context, request TRequest, next TypedNextFunc[TRequest, TResponse]) (TResponse, error)
+}
+
+// TypedNextFunc is a function that executes the next step of a pipeline for a typed pipeline behavior
+type TypedNextFunc[TRequest Request[TResponse], TResponse interface{}] func(ctx context.Context, request TRequest) (TResponse, error)
+
+// TypedPipelineBehaviorFunc is a function executed as part of the pipeline of a request type, it is a TypedPipelineBehavior
+type TypedPipelineBehaviorFunc[TRequest Request[TResponse], TResponse interface{}] func(ctx context.Context, request TRequest,
+	next TypedNextFunc[TRequest, TResponse]) (TResponse, error)
+
+func (f TypedPipelineBehaviorFunc[TRequest, TResponse]) Handle(ctx context.Context, request TRequest,
+	next TypedNextFunc[TRequest, TResponse]) (TResponse, error) {
+	return f(ctx, request, next)
+}
+
+// WithTypedPipelineBehavior adds a typed pipeline behavior to the container
+// It runs in its registration order among the other pipeline behaviors, for the requests of its type only
+func WithTypedPipelineBehavior[TRequest Request[TResponse], TResponse interface{}](pipelineBehavior TypedPipelineBehavior[TRequest, TResponse]) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.addContextPipelineBehavior(AdaptTypedPipelineBehavior[TRequest, TResponse](pipelineBehavior))
+	}
+}
+
+// AdaptTypedPipelineBehavior converts a TypedPipelineBehavior to a ContextPipelineBehavior
+// The adapted behavior passes the requests of other types directly to the next step
+func AdaptTypedPipelineBehavior[TRequest Request[TResponse], TResponse interface{}](pipelineBehavior TypedPipelineBehavior[TRequest, TResponse]) ContextPipelineBehavior {
+	return typedPipelineBehaviorAdapter[TRequest, TResponse]{
+		pipelineBehavior: pipelineBehavior,
+	}
+}
+
+type typedPipelineBehaviorAdapter[TRequest Request[TResponse], TResponse interface{}] struct {
+	pipelineBehavior TypedPipelineBehavior[TRequest, TResponse]
+}
+
+func (t typedPipelineBehaviorAdapter[TRequest, TResponse]) unwrap() interface{} {
+	return t.pipelineBehavior
+}
+
+func (t typedPipelineBehaviorAdapter[TRequest, TResponse]) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return next(ctx, request)
+	}
+
+	return t.pipelineBehavior.Handle(ctx, typedRequest, func(ctx context.Context, request TRequest) (TResponse, error) {
+		response, err := next(ctx, request)
+		if response == nil {
+			return *new(TResponse), err
+		}
+		typedResponse, ok := response.(TResponse)
+		if !ok {
+			if err != nil {
+				return *new(TResponse), err
+			}
+			return *new(TResponse), fmt.Errorf("response %T of request %T is not a %T", response, request, *new(TResponse))
+		}
+		return typedResponse, err
+	})
+}
@@ -0,0 +1,120 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestOtherRequest struct {
+	Value int
+}
+
+func (t TestOtherRequest) String() string {
+	return "TestOtherRequest"
+}
+
+type TestOtherRequestHandler struct {
+}
+
+func (t TestOtherRequestHandler) Handle(ctx context.Context, request TestOtherRequest) (int, error) {
+	return request.Value, nil
+}
+
+type TestValidationPipelineBehavior struct {
+	calls *[]string
+}
+
+func (t TestValidationPipelineBehavior) Handle(ctx context.Context, request *TestRequest, next mediator.TypedNextFunc[*TestRequest, string]) (string, error) {
+	*t.calls = append(*t.calls, "typed")
+	if request.Value == "" {
+		return "", errors.New("value is required")
+	}
+	response, err := next(ctx, &TestRequest{Value: request.Value + "-enriched"})
+	return response + "!", err
+}
+
+type TestRecordPipelineBehavior struct {
+	name  string
+	calls *[]string
+}
+
+func (t TestRecordPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+	*t.calls = append(*t.calls, t.name)
+	return next(ctx, request)
+}
+
+func TestTypedPipelineBehavior(t *testing.T) {
+	t.Run("should run for its request type", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](TestValidationPipelineBehavior{calls: &calls}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-enriched!", response)
+		assert.Equal(t, []string{"typed"}, calls)
+	})
+
+	t.Run("should short-circuit with an error", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](TestValidationPipelineBehavior{calls: &calls}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.EqualError(t, err, "pipeline of request *mediator_test.TestRequest failed: value is required")
+	})
+
+	t.Run("should not run for other request types", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[TestOtherRequest, int](TestOtherRequestHandler{})),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](TestValidationPipelineBehavior{calls: &calls}),
+		)
+
+		response, err := mediator.Send[TestOtherRequest, int](context.Background(), container, TestOtherRequest{Value: 42})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 42, response)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("should run in registration order with global behaviors", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestRecordPipelineBehavior{name: "first", calls: &calls}),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](TestValidationPipelineBehavior{calls: &calls}),
+			mediator.WithContextPipelineBehavior(TestRecordPipelineBehavior{name: "last", calls: &calls}),
+		)
+		sender := mediator.NewSender(container)
+
+		response, err := sender.Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-enriched!", response)
+		assert.Equal(t, []string{"first", "typed", "last"}, calls)
+	})
+
+	t.Run("should execute a typed function behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](mediator.TypedPipelineBehaviorFunc[*TestRequest, string](
+				func(ctx context.Context, request *TestRequest, next mediator.TypedNextFunc[*TestRequest, string]) (string, error) {
+					response, err := next(ctx, request)
+					return response + "!", err
+				})),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test!", response)
+	})
+}
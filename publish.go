@@ -2,11 +2,24 @@ package mediator
 
 import (
 	"context"
+	"errors"
+	"reflect"
 )
 
+// ErrStopPropagation is returned by a notification handler to stop the execution of the next handlers
+// It ends the synchronous and run-all strategies without being reported as a failure
+var ErrStopPropagation = errors.New("mediator: stop propagation")
+
 type PublishOptions struct {
 	NotificationDefinitionHandlers []NotificationHandlerDefinition
 	PublishStrategy                PublishStrategy
+	NotificationStrategies         []NotificationStrategy
+	NotificationBehaviors          []NotificationBehavior
+	NotificationHandlerBehaviors   []NotificationHandlerBehavior
+	RequiredNotificationTypes      []reflect.Type
+	StrictValidation               bool
+	UnhandledNotificationPolicy    UnhandledNotificationPolicy
+	DeadLetterHandler              DeadLetterHandler
 }
 
 // WithNotificationDefinitionHandler adds a notification handler to the container
@@ -30,9 +43,57 @@ func WithPublishStrategy(strategy PublishStrategy) func(*PublishOptions) {
 	}
 }
 
+// NotificationStrategy is the publish strategy of a notification type
+type NotificationStrategy struct {
+	// NotificationType is the type of the notifications, a notification implementing an interface type uses its strategy
+	NotificationType reflect.Type
+	Strategy         PublishStrategy
+}
+
+// WithNotificationPublishStrategy sets the strategy to publish the notifications of a type,
+// the strategy of the container is used for the other types
+// When the type is an interface, e.g. a base event, the strategy is used for the notifications implementing it
+// unless their own type has a strategy
+func WithNotificationPublishStrategy[TNotification Notification](strategy PublishStrategy) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.NotificationStrategies = append(options.NotificationStrategies, NotificationStrategy{
+			NotificationType: reflect.TypeOf((*TNotification)(nil)).Elem(),
+			Strategy:         strategy,
+		})
+	}
+}
+
+// WithNotificationBehavior adds a behavior wrapping the whole publication of a notification
+func WithNotificationBehavior(notificationBehavior NotificationBehavior) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.NotificationBehaviors = append(options.NotificationBehaviors, notificationBehavior)
+	}
+}
+
+// WithNotificationBehaviors adds behaviors wrapping the whole publication of a notification
+func WithNotificationBehaviors(notificationBehaviors ...NotificationBehavior) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.NotificationBehaviors = append(options.NotificationBehaviors, notificationBehaviors...)
+	}
+}
+
+// WithNotificationHandlerBehavior adds a behavior wrapping each invocation of a notification handler
+func WithNotificationHandlerBehavior(notificationHandlerBehavior NotificationHandlerBehavior) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.NotificationHandlerBehaviors = append(options.NotificationHandlerBehaviors, notificationHandlerBehavior)
+	}
+}
+
+// WithNotificationHandlerBehaviors adds behaviors wrapping each invocation of a notification handler
+func WithNotificationHandlerBehaviors(notificationHandlerBehaviors ...NotificationHandlerBehavior) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.NotificationHandlerBehaviors = append(options.NotificationHandlerBehaviors, notificationHandlerBehaviors...)
+	}
+}
+
 // Publisher is the interface to publish notifications
 type Publisher interface {
-	Publish(ctx context.Context, notification interface{}) error
+	Publish(ctx context.Context, notification interface{}, optFns ...func(*CallOptions)) error
 }
 
 type LaunchHandler func(context.Context, interface{}) error
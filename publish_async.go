@@ -0,0 +1,334 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is reported when a notification is rejected or dropped because the async queue is full
+var ErrQueueFull = errors.New("mediator: async publish queue is full")
+
+// ErrPublisherClosed is returned when a notification is published after the async publisher was shut down
+var ErrPublisherClosed = errors.New("mediator: async publisher is shut down")
+
+// OverflowPolicy is the behavior of an async publisher when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there is room in the queue, its context is done
+	// or the publisher is shut down, which fails the publication with ErrPublisherClosed
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the notification being published
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued notification to make room for the new one
+	OverflowDropOldest
+	// OverflowError rejects the notification being published with ErrQueueFull
+	OverflowError
+)
+
+const (
+	defaultAsyncQueueSize = 256
+	defaultAsyncWorkers   = 4
+)
+
+// AsyncPublishOptions configures the async publisher and the async publish strategy
+type AsyncPublishOptions struct {
+	// QueueSize is the number of notifications waiting for a worker, at least 1, 256 by default
+	QueueSize int
+	// Workers is the number of notifications processed at the same time, 4 by default
+	Workers int
+	// OverflowPolicy is the behavior when the queue is full, OverflowBlock by default
+	OverflowPolicy OverflowPolicy
+	// ErrorHandler receives the errors of the handlers, since the callers never see them,
+	// and ErrQueueFull for the dropped notifications
+	ErrorHandler func(ctx context.Context, err error)
+	// Strategy executes the handlers on the worker for the async publish strategy, synchronous by default
+	Strategy PublishStrategy
+}
+
+// WithQueueSize sets the number of notifications waiting for a worker
+func WithQueueSize(queueSize int) func(*AsyncPublishOptions) {
+	return func(options *AsyncPublishOptions) {
+		options.QueueSize = queueSize
+	}
+}
+
+// WithWorkers sets the number of notifications processed at the same time
+func WithWorkers(workers int) func(*AsyncPublishOptions) {
+	return func(options *AsyncPublishOptions) {
+		options.Workers = workers
+	}
+}
+
+// WithOverflowPolicy sets the behavior when the queue is full
+func WithOverflowPolicy(overflowPolicy OverflowPolicy) func(*AsyncPublishOptions) {
+	return func(options *AsyncPublishOptions) {
+		options.OverflowPolicy = overflowPolicy
+	}
+}
+
+// WithErrorHandler sets the callback receiving the errors of the background publications
+func WithErrorHandler(errorHandler func(ctx context.Context, err error)) func(*AsyncPublishOptions) {
+	return func(options *AsyncPublishOptions) {
+		options.ErrorHandler = errorHandler
+	}
+}
+
+// WithInnerStrategy sets the strategy executing the handlers on the worker
+func WithInnerStrategy(strategy PublishStrategy) func(*AsyncPublishOptions) {
+	return func(options *AsyncPublishOptions) {
+		options.Strategy = strategy
+	}
+}
+
+// AsyncPublisher is a publisher returning as soon as the notification is queued,
+// the handlers are executed on a background worker pool
+type AsyncPublisher interface {
+	Publisher
+	// Shutdown stops accepting notifications and waits for the queued ones to be processed,
+	// or for the context to be done
+	Shutdown(ctx context.Context) error
+}
+
+type asyncPublisher struct {
+	publisher Publisher
+	queue     *asyncQueue
+}
+
+// NewAsyncPublisher creates a publisher executing the whole publication of the notifications in background
+func NewAsyncPublisher(container PublishContainer, optFns ...func(*AsyncPublishOptions)) AsyncPublisher {
+	options := newAsyncPublishOptions(optFns)
+	return &asyncPublisher{
+		publisher: NewPublisher(container),
+		queue:     newAsyncQueue(options),
+	}
+}
+
+// Publish queues the notification, the call options apply when the notification is published in background
+// The result of WithCallResult is filled when the notification is queued, without the handlers,
+// the errors of the background publication go to the error handler
+func (a *asyncPublisher) Publish(ctx context.Context, notification interface{}, optFns ...func(*CallOptions)) error {
+	var options CallOptions
+	for _, optFn := range optFns {
+		optFn(&options)
+	}
+	if options.Result != nil {
+		// The background publication does not write the result, the caller may read it meanwhile
+		optFns = append(optFns[:len(optFns):len(optFns)], WithCallResult(nil))
+	}
+	start := time.Now()
+	err := a.queue.enqueue(ctx, func(ctx context.Context) error {
+		err := a.publisher.Publish(ctx, notification, optFns...)
+		if err != nil {
+			return fmt.Errorf("publish %T: %w", notification, err)
+		}
+		return nil
+	})
+	if options.Result != nil {
+		*options.Result = CallResult{
+			Duration: time.Since(start),
+			Err:      err,
+		}
+	}
+	return err
+}
+
+func (a *asyncPublisher) Shutdown(ctx context.Context) error {
+	return a.queue.shutdown(ctx)
+}
+
+// AsyncPublishStrategy is a publish strategy returning as soon as the handlers are queued
+type AsyncPublishStrategy interface {
+	PublishStrategy
+	// Shutdown stops accepting notifications and waits for the queued ones to be processed,
+	// or for the context to be done
+	Shutdown(ctx context.Context) error
+}
+
+type asyncPublishStrategy struct {
+	strategy PublishStrategy
+	queue    *asyncQueue
+}
+
+// NewAsyncPublishStrategy creates a strategy executing the handlers in background with the inner strategy
+// The notification behaviors wrapping the whole publication complete as soon as the handlers are queued,
+// the notification handler behaviors run in background with the handlers
+func NewAsyncPublishStrategy(optFns ...func(*AsyncPublishOptions)) AsyncPublishStrategy {
+	options := newAsyncPublishOptions(optFns)
+	strategy := options.Strategy
+	if strategy == nil {
+		strategy = NewSynchronousPublishStrategy()
+	}
+	return &asyncPublishStrategy{
+		strategy: strategy,
+		queue:    newAsyncQueue(options),
+	}
+}
+
+func (a *asyncPublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher LaunchHandler) error {
+	return a.queue.enqueue(ctx, func(ctx context.Context) error {
+		return a.strategy.Execute(ctx, handlers, launcher)
+	})
+}
+
+func (a *asyncPublishStrategy) Shutdown(ctx context.Context) error {
+	return a.queue.shutdown(ctx)
+}
+
+func newAsyncPublishOptions(optFns []func(*AsyncPublishOptions)) *AsyncPublishOptions {
+	options := &AsyncPublishOptions{
+		QueueSize: defaultAsyncQueueSize,
+		Workers:   defaultAsyncWorkers,
+	}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 1
+	}
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	return options
+}
+
+type asyncJob struct {
+	ctx context.Context
+	run func(ctx context.Context) error
+}
+
+// asyncQueue is the bounded queue and the worker pool shared by the async publisher and strategy
+type asyncQueue struct {
+	jobs           chan asyncJob
+	overflowPolicy OverflowPolicy
+	errorHandler   func(ctx context.Context, err error)
+
+	// closing is closed by the shutdown, the producers blocked on a full queue give up on it
+	closing chan struct{}
+	// mu protects closed and the count of the senders, it is never held while sending on jobs
+	mu     sync.Mutex
+	closed bool
+	// senders are the producers sending on jobs, the channel is closed once they are gone
+	senders sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+func newAsyncQueue(options *AsyncPublishOptions) *asyncQueue {
+	q := &asyncQueue{
+		jobs:           make(chan asyncJob, options.QueueSize),
+		closing:        make(chan struct{}),
+		overflowPolicy: options.OverflowPolicy,
+		errorHandler:   options.ErrorHandler,
+	}
+	q.workers.Add(options.Workers)
+	for i := 0; i < options.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *asyncQueue) enqueue(ctx context.Context, run func(ctx context.Context) error) error {
+	// The job outlives the caller, it keeps the values of its context but not its cancellation
+	job := asyncJob{
+		ctx: context.WithoutCancel(ctx),
+		run: run,
+	}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrPublisherClosed
+	}
+	q.senders.Add(1)
+	q.mu.Unlock()
+	defer q.senders.Done()
+
+	switch q.overflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case q.jobs <- job:
+		default:
+			q.report(job.ctx, ErrQueueFull)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.jobs <- job:
+				return nil
+			default:
+			}
+			select {
+			case dropped := <-q.jobs:
+				q.report(dropped.ctx, ErrQueueFull)
+			default:
+			}
+		}
+	case OverflowError:
+		select {
+		case q.jobs <- job:
+		default:
+			return ErrQueueFull
+		}
+	default:
+		select {
+		case q.jobs <- job:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-q.closing:
+			return ErrPublisherClosed
+		}
+	}
+	return nil
+}
+
+func (q *asyncQueue) work() {
+	defer q.workers.Done()
+	for job := range q.jobs {
+		q.report(job.ctx, q.run(job))
+	}
+}
+
+func (q *asyncQueue) run(job asyncJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mediator: async publication panicked: %v", r)
+		}
+	}()
+	return job.run(job.ctx)
+}
+
+func (q *asyncQueue) report(ctx context.Context, err error) {
+	if err != nil && q.errorHandler != nil {
+		q.errorHandler(ctx, err)
+	}
+}
+
+func (q *asyncQueue) shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.closing)
+		// The blocked senders give up on closing, the workers drain the queue once they are gone
+		go func() {
+			q.senders.Wait()
+			close(q.jobs)
+		}()
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,225 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type TestBlockingNotificationHandler struct {
+	entered atomic.Int32
+	release chan struct{}
+	handled atomic.Int32
+	err     error
+}
+
+func (h *TestBlockingNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	h.entered.Add(1)
+	<-h.release
+	h.handled.Add(1)
+	return h.err
+}
+
+func newBlockingContainer(handler *TestBlockingNotificationHandler, optFns ...func(*mediator.PublishOptions)) mediator.PublishContainer {
+	optFns = append(optFns, mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)))
+	return mediator.NewPublishContainer(optFns...)
+}
+
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) handle(ctx context.Context, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestAsyncPublisher(t *testing.T) {
+	t.Run("should return before the handlers and drain on shutdown", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler))
+
+		for i := 0; i < 3; i++ {
+			err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(0), handler.handled.Load())
+
+		close(handler.release)
+		err := publisher.Shutdown(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), handler.handled.Load())
+	})
+
+	t.Run("should report the handler errors to the error handler", func(t *testing.T) {
+		handlerErr := errors.New("smtp unavailable")
+		recorder := &errorRecorder{}
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{}), err: handlerErr}
+		close(handler.release)
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler), mediator.WithErrorHandler(recorder.handle))
+
+		err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, publisher.Shutdown(context.Background()))
+		errs := recorder.errors()
+		assert.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], handlerErr)
+	})
+
+	t.Run("should reject notifications after shutdown", func(t *testing.T) {
+		publisher := mediator.NewAsyncPublisher(mediator.NewPublishContainer())
+		assert.NoError(t, publisher.Shutdown(context.Background()))
+
+		err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrPublisherClosed)
+	})
+
+	t.Run("should stop waiting when the shutdown context is done", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		defer close(handler.release)
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler))
+		assert.NoError(t, publisher.Publish(context.Background(), TestNotification{Value: "test"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := publisher.Shutdown(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should keep running after the caller context is cancelled", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler))
+		ctx, cancel := context.WithCancel(context.Background())
+
+		assert.NoError(t, publisher.Publish(ctx, TestNotification{Value: "test"}))
+		cancel()
+		close(handler.release)
+
+		assert.NoError(t, publisher.Shutdown(context.Background()))
+		assert.Equal(t, int32(1), handler.handled.Load())
+	})
+
+	t.Run("should not wait for the blocked producers beyond the shutdown deadline", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		defer close(handler.release)
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler),
+			mediator.WithWorkers(1),
+			mediator.WithQueueSize(1),
+		)
+
+		// the first notification is taken by the worker, the second one fills the queue and the third one blocks
+		assert.NoError(t, publisher.Publish(context.Background(), TestNotification{Value: "first"}))
+		assert.Eventually(t, func() bool {
+			return handler.entered.Load() == 1
+		}, time.Second, time.Millisecond)
+		assert.NoError(t, publisher.Publish(context.Background(), TestNotification{Value: "second"}))
+		blocked := make(chan error, 1)
+		go func() {
+			blocked <- publisher.Publish(context.Background(), TestNotification{Value: "third"})
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := publisher.Shutdown(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+
+		select {
+		case err := <-blocked:
+			assert.ErrorIs(t, err, mediator.ErrPublisherClosed)
+		case <-time.After(time.Second):
+			assert.Fail(t, "the blocked producer was not released by the shutdown")
+		}
+		assert.ErrorIs(t, publisher.Publish(context.Background(), TestNotification{Value: "fourth"}), mediator.ErrPublisherClosed)
+	})
+
+	t.Run("should fill the call result when the notification is queued", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler))
+
+		var result mediator.CallResult
+		err := publisher.Publish(context.Background(), TestNotification{Value: "test"}, mediator.WithCallResult(&result))
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+		assert.Empty(t, result.Handlers)
+
+		// The background publication does not write the result while it is read
+		close(handler.release)
+		assert.NoError(t, publisher.Shutdown(context.Background()))
+		assert.Equal(t, int32(1), handler.handled.Load())
+		assert.Empty(t, result.Handlers)
+	})
+
+	overflows := map[string]struct {
+		policy       mediator.OverflowPolicy
+		publishErr   error
+		handled      int32
+		reportedErrs int
+	}{
+		"error":       {policy: mediator.OverflowError, publishErr: mediator.ErrQueueFull, handled: 2},
+		"drop newest": {policy: mediator.OverflowDropNewest, handled: 2, reportedErrs: 1},
+		"drop oldest": {policy: mediator.OverflowDropOldest, handled: 2, reportedErrs: 1},
+	}
+	for name, overflow := range overflows {
+		t.Run("should apply the "+name+" overflow policy", func(t *testing.T) {
+			recorder := &errorRecorder{}
+			handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+			publisher := mediator.NewAsyncPublisher(newBlockingContainer(handler),
+				mediator.WithWorkers(1),
+				mediator.WithQueueSize(1),
+				mediator.WithOverflowPolicy(overflow.policy),
+				mediator.WithErrorHandler(recorder.handle),
+			)
+
+			// the first notification is taken by the worker, the second one fills the queue
+			assert.NoError(t, publisher.Publish(context.Background(), TestNotification{Value: "first"}))
+			assert.Eventually(t, func() bool {
+				return handler.entered.Load() == 1
+			}, time.Second, time.Millisecond)
+			assert.NoError(t, publisher.Publish(context.Background(), TestNotification{Value: "second"}))
+			err := publisher.Publish(context.Background(), TestNotification{Value: "third"})
+			if overflow.publishErr != nil {
+				assert.ErrorIs(t, err, overflow.publishErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			close(handler.release)
+			assert.NoError(t, publisher.Shutdown(context.Background()))
+			assert.Equal(t, overflow.handled, handler.handled.Load())
+			assert.Len(t, recorder.errors(), overflow.reportedErrs)
+		})
+	}
+}
+
+func TestAsyncPublishStrategy(t *testing.T) {
+	t.Run("should execute the handlers in background", func(t *testing.T) {
+		handler := &TestBlockingNotificationHandler{release: make(chan struct{})}
+		strategy := mediator.NewAsyncPublishStrategy(mediator.WithInnerStrategy(mediator.NewParallelPublishStrategy()))
+		container := newBlockingContainer(handler, mediator.WithPublishStrategy(strategy))
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(0), handler.handled.Load())
+
+		close(handler.release)
+		assert.NoError(t, strategy.Shutdown(context.Background()))
+		assert.Equal(t, int32(1), handler.handled.Load())
+	})
+}
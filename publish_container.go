@@ -2,8 +2,11 @@ package mediator
 
 import (
 	"context"
-	"fmt"
 	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 // PublishWithoutContext publishes a notification to multiple handlers without a context
@@ -12,48 +15,362 @@ func PublishWithoutContext[TNotification Notification](container PublishContaine
 }
 
 // Publish publishes a notification to multiple handlers
-func Publish[TNotification Notification](ctx context.Context, container PublishContainer, notification TNotification) error {
-	handlers := container.resolve(notification)
-	if handlers == nil {
-		return nil
+// The call options apply to this call only
+func Publish[TNotification Notification](ctx context.Context, container PublishContainer, notification TNotification,
+	optFns ...func(*CallOptions)) error {
+	call, ctx := newCall(ctx, optFns)
+	resolved := container.resolveNotification(notification)
+	if resolved == nil {
+		return call.endNotification(nil, container.publishUnhandled(ctx, notification))
 	}
 
-	return container.getStrategy().Execute(ctx, handlers, func(handlerCtx context.Context, handler interface{}) error {
-		handlerValue, ok := handler.(NotificationHandler[TNotification])
-		if !ok {
-			return fmt.Errorf("handler for notification %T is not a NotificationHandler", notification)
-		}
-		err := handlerValue.Handle(handlerCtx, notification)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	err := container.executeWithBehaviors(ctx, notification, resolved, call.callOptions())
+	return call.endNotification(resolved.handlers, err)
 }
 
 // PublishContainer is the mediator container for request and notification handlers
 // It is responsible for resolving handlers and pipeline behaviors
+// Handlers and behaviors can be registered on a live container, safely with the notifications being published
 type PublishContainer interface {
-	resolve(notification interface{}) []interface{}
-	getStrategy() PublishStrategy
+	// Validate reports the misconfigurations of the container
+	Validate() error
+	// RegisterNotificationHandler registers a notification handler after the other handlers of its order
+	RegisterNotificationHandler(definition NotificationHandlerDefinition) Unregister
+	// RegisterNotificationBehavior registers a notification behavior after the other behaviors
+	RegisterNotificationBehavior(behavior NotificationBehavior) Unregister
+	// RegisterNotificationHandlerBehavior registers a notification handler behavior after the other handler behaviors
+	RegisterNotificationHandlerBehavior(behavior NotificationHandlerBehavior) Unregister
+	resolveNotification(notification interface{}) *resolvedNotification
+	publishUnhandled(ctx context.Context, notification interface{}) error
+	executeWithBehaviors(ctx context.Context,
+		notification interface{},
+		resolved *resolvedNotification,
+		options *CallOptions) error
 }
 
 type notificationContainer struct {
-	notificationHandlers map[reflect.Type][]interface{}
-	strategy             PublishStrategy
+	// state is the snapshot used by the publication, it is replaced on each registration
+	state atomic.Pointer[notificationState]
+	// mu serializes the registrations
+	mu       sync.Mutex
+	lastID   uint64
+	strategy PublishStrategy
+	// strategies are the strategies of the concrete notification types
+	strategies map[reflect.Type]PublishStrategy
+	// interfaceStrategies are the strategies of the interface notification types, in registration order
+	interfaceStrategies []NotificationStrategy
+	requiredTypes       []reflect.Type
+	unhandledPolicy     UnhandledNotificationPolicy
+	deadLetter          DeadLetterHandler
+}
+
+// publishRegistrations are the handlers and behaviors registered on a publish container
+type publishRegistrations struct {
+	definitions      registrations[NotificationHandlerDefinition]
+	behaviors        registrations[NotificationBehavior]
+	handlerBehaviors registrations[NotificationHandlerBehavior]
+}
+
+// notificationState is an immutable snapshot of a publish container
+type notificationState struct {
+	registrations publishRegistrations
+	// notificationHandlers are the handlers registered for a concrete notification type
+	notificationHandlers map[reflect.Type][]*notificationHandlerEntry
+	// interfaceHandlers are the handlers registered for an interface, e.g. a base event or Notification itself
+	interfaceHandlers []*notificationHandlerEntry
+	// resolved caches the handlers resolved for each notification type
+	resolved         sync.Map
+	behaviors        []NotificationBehavior
+	handlerBehaviors []NotificationHandlerBehavior
+}
+
+// notificationHandlerEntry is a registered notification handler with the function invoking it
+type notificationHandlerEntry struct {
+	notificationType reflect.Type
+	handler          interface{}
+	invoke           func(ctx context.Context, notification interface{}) error
+	order            int
+	// sequence is the registration order, it breaks the ties between handlers with the same order
+	sequence int
+}
+
+func newNotificationHandlerEntry(definition NotificationHandlerDefinition, sequence int) *notificationHandlerEntry {
+	handler := definition.Handler()
+	entry := &notificationHandlerEntry{
+		notificationType: definition.NotificationType(),
+		handler:          handler,
+		order:            notificationHandlerOrder(definition),
+		sequence:         sequence,
+	}
+	if invoker, ok := definition.(notificationHandlerInvoker); ok {
+		entry.invoke = invoker.invokeHandler
+	} else {
+		// The definition is not generic, the handler can only be called with reflection
+		entry.invoke = func(ctx context.Context, notification interface{}) error {
+			return invokeNotificationHandler(ctx, handler, notification)
+		}
+	}
+	return entry
+}
+
+// resolvedNotification are the handlers resolved for a notification type
+// The publish strategies are given the handlers registered by the user, the launcher finds their entry back
+type resolvedNotification struct {
+	// state is the snapshot the handlers were resolved with, the publication runs entirely with it
+	state *notificationState
+	// handlers are the handlers registered by the user, by ascending order then registration order
+	handlers []interface{}
+	// entries are the entries of the handlers, by identity of the handler
+	entries map[interface{}][]*notificationHandlerEntry
+	// duplicated reports whether a handler is registered for several types matching the notification
+	duplicated bool
+}
+
+func newResolvedNotification(state *notificationState, entries []*notificationHandlerEntry) *resolvedNotification {
+	resolved := &resolvedNotification{
+		state:    state,
+		handlers: make([]interface{}, 0, len(entries)),
+		entries:  make(map[interface{}][]*notificationHandlerEntry, len(entries)),
+	}
+	for _, entry := range entries {
+		resolved.handlers = append(resolved.handlers, entry.handler)
+		key := handlerIdentity(entry.handler)
+		resolved.entries[key] = append(resolved.entries[key], entry)
+		resolved.duplicated = resolved.duplicated || len(resolved.entries[key]) > 1
+	}
+	return resolved
+}
+
+// launcher returns the function launching the handlers given to the publish strategy
+// The entry of the handler is chosen once per launch, before the handler behaviors run, so a behavior calling next
+// again, e.g. to retry, invokes the same entry. A handler launched several times, e.g. a handler registered for
+// a notification type and for an interface it implements, runs its entries in resolution order.
+// A handler which was not resolved by the container is called with reflection
+func (r *resolvedNotification) launcher(notification interface{}, handlerBehaviors []NotificationHandlerBehavior) LaunchHandler {
+	entryOf := r.entryOf
+	if r.duplicated {
+		var mu sync.Mutex
+		launches := make(map[interface{}]int)
+		entryOf = func(handler interface{}) *notificationHandlerEntry {
+			key := handlerIdentity(handler)
+			entries := r.entries[key]
+			if len(entries) == 0 {
+				return nil
+			}
+			mu.Lock()
+			launch := launches[key]
+			launches[key]++
+			mu.Unlock()
+			return entries[launch%len(entries)]
+		}
+	}
+	return func(ctx context.Context, handler interface{}) error {
+		entry := entryOf(handler)
+		if len(handlerBehaviors) == 0 {
+			return invokeEntry(ctx, entry, handler, notification)
+		}
+		var next NotificationNextFunc = func(ctx context.Context) error {
+			return invokeEntry(ctx, entry, handler, notification)
+		}
+		for i := len(handlerBehaviors) - 1; i >= 0; i-- {
+			behavior := handlerBehaviors[i]
+			inner := next
+			next = func(ctx context.Context) error {
+				return behavior.Handle(ctx, notification, handler, inner)
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// entryOf returns the first entry of the handler, nil when the handler was not resolved
+func (r *resolvedNotification) entryOf(handler interface{}) *notificationHandlerEntry {
+	if entries := r.entries[handlerIdentity(handler)]; len(entries) > 0 {
+		return entries[0]
+	}
+	return nil
+}
+
+// invokeEntry invokes the entry of the handler, or the handler with reflection when it has no entry
+func invokeEntry(ctx context.Context, entry *notificationHandlerEntry, handler interface{}, notification interface{}) error {
+	if entry == nil {
+		return invokeNotificationHandler(ctx, handler, notification)
+	}
+	return entry.invoke(ctx, notification)
+}
+
+// handlerPointer identifies the handlers which cannot be compared, e.g. the functions,
+// by the data word of the interface holding them: the copies of a handler share it
+type handlerPointer struct {
+	handlerType reflect.Type
+	data        unsafe.Pointer
+}
+
+// handlerIdentity returns the key identifying a handler, the handler itself when it can be compared
+func handlerIdentity(handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	if !value.IsValid() || value.Comparable() {
+		return handler
+	}
+	// An interface is a type word followed by a data word, the data word of a closure points to the closure
+	// and not to its code, so the functions built by the same helper are told apart
+	return handlerPointer{
+		handlerType: value.Type(),
+		data:        (*[2]unsafe.Pointer)(unsafe.Pointer(&handler))[1],
+	}
+}
+
+func (n *notificationContainer) executeWithBehaviors(ctx context.Context,
+	notification interface{},
+	resolved *resolvedNotification,
+	options *CallOptions) error {
+	state := resolved.state
+	strategy := options.publishStrategy(n.strategyOf(notification))
+	behaviors := options.notificationBehaviors(state.behaviors)
+	handlerBehaviors := options.notificationHandlerBehaviors(state.handlerBehaviors)
+	handlers := resolved.handlers
+	launcher := resolved.launcher(notification, handlerBehaviors)
+
+	if len(behaviors) == 0 {
+		return strategy.Execute(ctx, handlers, launcher)
+	}
+
+	var next NotificationNextFunc = func(ctx context.Context) error {
+		return strategy.Execute(ctx, handlers, launcher)
+	}
+	for i := len(behaviors) - 1; i >= 0; i-- {
+		behavior := behaviors[i]
+		inner := next
+		next = func(ctx context.Context) error {
+			return behavior.Handle(ctx, notification, inner)
+		}
+	}
+
+	return next(ctx)
 }
 
-func (n notificationContainer) getStrategy() PublishStrategy {
+// strategyOf returns the strategy of the notification type, the strategy of the container when it has none
+func (n *notificationContainer) strategyOf(notification interface{}) PublishStrategy {
+	if len(n.strategies) == 0 && len(n.interfaceStrategies) == 0 {
+		return n.strategy
+	}
+	notificationType := reflect.TypeOf(notification)
+	if strategy, ok := n.strategies[notificationType]; ok {
+		return strategy
+	}
+	for _, strategy := range n.interfaceStrategies {
+		if notificationType.Implements(strategy.NotificationType) {
+			return strategy.Strategy
+		}
+	}
 	return n.strategy
 }
 
-func (n notificationContainer) resolve(notification interface{}) []interface{} {
+// resolve returns the handlers of the notification type and of the interfaces it implements,
+// by ascending order then registration order
+func (n *notificationContainer) resolveNotification(notification interface{}) *resolvedNotification {
 	notificationType := reflect.TypeOf(notification)
-	results, ok := n.notificationHandlers[notificationType]
-	if !ok {
+	if notificationType == nil {
 		return nil
 	}
-	return results
+	return n.state.Load().resolveType(notificationType)
+}
+
+func (n *notificationState) resolveType(notificationType reflect.Type) *resolvedNotification {
+	if resolved, ok := n.resolved.Load(notificationType); ok {
+		return resolved.(*resolvedNotification)
+	}
+
+	entries := append([]*notificationHandlerEntry(nil), n.notificationHandlers[notificationType]...)
+	for _, entry := range n.interfaceHandlers {
+		if notificationType.Implements(entry.notificationType) {
+			entries = append(entries, entry)
+		}
+	}
+	var resolved *resolvedNotification
+	if len(entries) > 0 {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].order != entries[j].order {
+				return entries[i].order < entries[j].order
+			}
+			return entries[i].sequence < entries[j].sequence
+		})
+		resolved = newResolvedNotification(n, entries)
+	}
+
+	actual, _ := n.resolved.LoadOrStore(notificationType, resolved)
+	return actual.(*resolvedNotification)
+}
+
+func (n *notificationContainer) RegisterNotificationHandler(definition NotificationHandlerDefinition) Unregister {
+	return n.register(func(registrations *publishRegistrations, id uint64) {
+		registrations.definitions = registrations.definitions.with(id, definition)
+	}, func(registrations *publishRegistrations, id uint64) (removed bool) {
+		registrations.definitions, removed = registrations.definitions.without(id)
+		return removed
+	})
+}
+
+func (n *notificationContainer) RegisterNotificationBehavior(behavior NotificationBehavior) Unregister {
+	return n.register(func(registrations *publishRegistrations, id uint64) {
+		registrations.behaviors = registrations.behaviors.with(id, behavior)
+	}, func(registrations *publishRegistrations, id uint64) (removed bool) {
+		registrations.behaviors, removed = registrations.behaviors.without(id)
+		return removed
+	})
+}
+
+func (n *notificationContainer) RegisterNotificationHandlerBehavior(behavior NotificationHandlerBehavior) Unregister {
+	return n.register(func(registrations *publishRegistrations, id uint64) {
+		registrations.handlerBehaviors = registrations.handlerBehaviors.with(id, behavior)
+	}, func(registrations *publishRegistrations, id uint64) (removed bool) {
+		registrations.handlerBehaviors, removed = registrations.handlerBehaviors.without(id)
+		return removed
+	})
+}
+
+// register applies a registration and publishes the new snapshot,
+// the returned Unregister removes it the same way
+func (n *notificationContainer) register(add func(*publishRegistrations, uint64),
+	remove func(*publishRegistrations, uint64) bool) Unregister {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lastID++
+	id := n.lastID
+	registrations := n.state.Load().registrations
+	add(&registrations, id)
+	n.state.Store(newNotificationState(registrations))
+
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		registrations := n.state.Load().registrations
+		if remove(&registrations, id) {
+			n.state.Store(newNotificationState(registrations))
+		}
+	}
+}
+
+// newNotificationState creates the snapshot of the registrations
+func newNotificationState(registrations publishRegistrations) *notificationState {
+	notificationHandlers := make(map[reflect.Type][]*notificationHandlerEntry, len(registrations.definitions))
+	var interfaceHandlers []*notificationHandlerEntry
+	for _, registration := range registrations.definitions {
+		// The ids follow the registration order
+		entry := newNotificationHandlerEntry(registration.value, int(registration.id))
+		if entry.notificationType.Kind() == reflect.Interface {
+			interfaceHandlers = append(interfaceHandlers, entry)
+		} else {
+			notificationHandlers[entry.notificationType] = append(notificationHandlers[entry.notificationType], entry)
+		}
+	}
+	return &notificationState{
+		registrations:        registrations,
+		notificationHandlers: notificationHandlers,
+		interfaceHandlers:    interfaceHandlers,
+		behaviors:            registrations.behaviors.values(),
+		handlerBehaviors:     registrations.handlerBehaviors.values(),
+	}
 }
 
 func NewPublishContainer(optFns ...func(*PublishOptions)) PublishContainer {
@@ -61,23 +378,51 @@ func NewPublishContainer(optFns ...func(*PublishOptions)) PublishContainer {
 	for _, optFn := range optFns {
 		optFn(options)
 	}
-	notificationDefinitionHandlers := options.NotificationDefinitionHandlers
-	notificationHandlers := make(map[reflect.Type][]interface{}, len(notificationDefinitionHandlers))
-	for _, notificationHandler := range notificationDefinitionHandlers {
-		if handlers, ok := notificationHandlers[notificationHandler.NotificationType()]; ok {
-			handlers = append(handlers, notificationHandler.Handler())
-			notificationHandlers[notificationHandler.NotificationType()] = handlers
-		} else {
-			notificationHandlers[notificationHandler.NotificationType()] = []interface{}{notificationHandler.Handler()}
-		}
-	}
+	return newNotificationContainer(options)
+}
+
+func newNotificationContainer(options *PublishOptions) *notificationContainer {
 	strategy := options.PublishStrategy
 	if strategy == nil {
 		strategy = NewSynchronousPublishStrategy()
 	}
-
-	return &notificationContainer{
-		notificationHandlers: notificationHandlers,
-		strategy:             strategy,
+	container := &notificationContainer{
+		strategy:        strategy,
+		requiredTypes:   options.RequiredNotificationTypes,
+		unhandledPolicy: options.UnhandledNotificationPolicy,
+		deadLetter:      options.DeadLetterHandler,
+	}
+	for _, notificationStrategy := range options.NotificationStrategies {
+		if notificationStrategy.Strategy == nil {
+			continue
+		}
+		if notificationStrategy.NotificationType.Kind() == reflect.Interface {
+			container.interfaceStrategies = append(container.interfaceStrategies, notificationStrategy)
+			continue
+		}
+		if container.strategies == nil {
+			container.strategies = make(map[reflect.Type]PublishStrategy)
+		}
+		container.strategies[notificationStrategy.NotificationType] = notificationStrategy.Strategy
+	}
+	var registrations publishRegistrations
+	for _, definition := range options.NotificationDefinitionHandlers {
+		container.lastID++
+		registrations.definitions = registrations.definitions.with(container.lastID, definition)
+	}
+	for _, behavior := range options.NotificationBehaviors {
+		container.lastID++
+		registrations.behaviors = registrations.behaviors.with(container.lastID, behavior)
+	}
+	for _, behavior := range options.NotificationHandlerBehaviors {
+		container.lastID++
+		registrations.handlerBehaviors = registrations.handlerBehaviors.with(container.lastID, behavior)
+	}
+	container.state.Store(newNotificationState(registrations))
+	if options.StrictValidation {
+		if err := container.Validate(); err != nil {
+			panic(err)
+		}
 	}
+	return container
 }
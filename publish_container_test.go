@@ -2,8 +2,11 @@ package mediator_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/Oleexo/mediator-go"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"testing"
 )
 
@@ -29,6 +32,78 @@ func (h *TestNotificationHandler2) Handle(ctx context.Context, notification Test
 	return nil
 }
 
+type TestOrderedNotificationHandler struct {
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (h *TestOrderedNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	*h.calls = append(*h.calls, h.name)
+	return h.err
+}
+
+type TestDomainEvent interface {
+	AggregateID() string
+}
+
+type TestUserCreated struct {
+	ID string
+}
+
+func (e TestUserCreated) AggregateID() string {
+	return e.ID
+}
+
+type TestAuditHandler struct {
+	events []string
+}
+
+func (h *TestAuditHandler) Handle(ctx context.Context, event TestDomainEvent) error {
+	h.events = append(h.events, event.AggregateID())
+	return nil
+}
+
+type TestCatchAllHandler struct {
+	notifications []mediator.Notification
+}
+
+func (h *TestCatchAllHandler) Handle(ctx context.Context, notification mediator.Notification) error {
+	h.notifications = append(h.notifications, notification)
+	return nil
+}
+
+// TestReversePublishStrategy records the handlers it is given and launches them in reverse order
+type TestReversePublishStrategy struct {
+	handlers *[]interface{}
+}
+
+func (t TestReversePublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher mediator.LaunchHandler) error {
+	*t.handlers = append(*t.handlers, handlers...)
+	for i := len(handlers) - 1; i >= 0; i-- {
+		if err := launcher(ctx, handlers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTestFuncNotificationHandler returns a function handler failing with the error, each call builds a new closure
+func newTestFuncNotificationHandler(err error) mediator.NotificationHandlerFunc[TestNotification] {
+	return func(ctx context.Context, notification TestNotification) error {
+		return err
+	}
+}
+
+// TestReplacePublishStrategy launches its own handler instead of the resolved handlers
+type TestReplacePublishStrategy struct {
+	handler interface{}
+}
+
+func (t TestReplacePublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher mediator.LaunchHandler) error {
+	return launcher(ctx, t.handler)
+}
+
 func TestPublishContainer(t *testing.T) {
 	t.Run("With no handlers", func(t *testing.T) {
 		container := mediator.NewPublishContainer()
@@ -67,4 +142,167 @@ func TestPublishContainer(t *testing.T) {
 		assert.True(t, handler2.Executed)
 	})
 
+	t.Run("With ordered handlers", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "audit", calls: &calls}, mediator.WithHandlerOrder(100)),
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "default", calls: &calls}),
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "validation", calls: &calls}, mediator.WithHandlerOrder(-10)),
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "default2", calls: &calls}),
+			),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"validation", "default", "default2", "audit"}, calls)
+	})
+
+	strategies := map[string]func(*mediator.PublishOptions){
+		"synchronous": mediator.WithSynchronousPublishStrategy(),
+		"run all":     mediator.WithRunAllPublishStrategy(),
+	}
+	for name, strategy := range strategies {
+		t.Run(name+" with a handler stopping the propagation", func(t *testing.T) {
+			var calls []string
+			container := mediator.NewPublishContainer(
+				mediator.WithNotificationDefinitionHandlers(
+					mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "first", calls: &calls}),
+					mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "stop", calls: &calls, err: fmt.Errorf("duplicate: %w", mediator.ErrStopPropagation)}),
+					mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "last", calls: &calls}),
+				),
+				strategy,
+			)
+
+			err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"first", "stop"}, calls)
+		})
+	}
+
+	t.Run("With interface and catch-all handlers", func(t *testing.T) {
+		auditHandler := &TestAuditHandler{}
+		catchAllHandler := &TestCatchAllHandler{}
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestDomainEvent](auditHandler),
+				mediator.NewNotificationHandlerDefinition[mediator.Notification](catchAllHandler),
+				mediator.NewNotificationHandlerDefinition[TestNotification](handler),
+			),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestUserCreated{ID: "42"}))
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		assert.NoError(t, mediator.NewPublisher(container).Publish(context.Background(), TestUserCreated{ID: "43"}))
+
+		assert.Equal(t, []string{"42", "43"}, auditHandler.events)
+		assert.Equal(t, []mediator.Notification{TestUserCreated{ID: "42"}, TestNotification{Value: "test"}, TestUserCreated{ID: "43"}}, catchAllHandler.notifications)
+		assert.True(t, handler.Executed)
+	})
+
+	t.Run("With interface handlers ordered with concrete handlers", func(t *testing.T) {
+		var calls []string
+		auditHandler := &TestAuditHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "concrete", calls: &calls}, mediator.WithHandlerOrder(1)),
+				mediator.NewNotificationHandlerDefinition[mediator.Notification](&TestCatchAllHandler{}),
+				mediator.NewNotificationHandlerDefinition[TestDomainEvent](auditHandler),
+			),
+			mediator.WithNotificationHandlerBehavior(TestRecordNotificationHandlerBehavior{mu: &sync.Mutex{}, calls: &calls}),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		assert.Equal(t, []string{"*mediator_test.TestCatchAllHandler", "*mediator_test.TestOrderedNotificationHandler", "concrete"}, calls)
+		assert.Empty(t, auditHandler.events)
+	})
+
+	t.Run("should publish with the strategy of the notification type", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		auditHandler := &TestAuditHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestDomainEvent](auditHandler)),
+			mediator.WithPublishStrategy(TestRejectPublishStrategy{}),
+			mediator.WithNotificationPublishStrategy[TestNotification](mediator.NewSynchronousPublishStrategy()),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		assert.True(t, handler.Executed)
+		assert.EqualError(t, mediator.PublishWithoutContext(container, TestUserCreated{ID: "1"}), "rejected")
+
+		// The strategy of the call overrides the strategy of the type
+		handler.Executed = false
+		err := mediator.Publish(context.Background(), container, TestNotification{Value: "test"},
+			mediator.WithCallPublishStrategy(TestRejectPublishStrategy{}))
+		assert.EqualError(t, err, "rejected")
+		assert.False(t, handler.Executed)
+	})
+
+	t.Run("should publish with the strategy of an interface implemented by the notification", func(t *testing.T) {
+		auditHandler := &TestAuditHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestDomainEvent](auditHandler)),
+			mediator.WithPublishStrategy(TestRejectPublishStrategy{}),
+			mediator.WithNotificationPublishStrategy[TestDomainEvent](mediator.NewSynchronousPublishStrategy()),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestUserCreated{ID: "1"}))
+		assert.Equal(t, []string{"1"}, auditHandler.events)
+	})
+	t.Run("should give the registered handlers to the publish strategy", func(t *testing.T) {
+		var calls []string
+		var handlers []interface{}
+		first := &TestOrderedNotificationHandler{name: "first", calls: &calls}
+		second := mediator.NotificationHandlerFunc[TestNotification](func(ctx context.Context, notification TestNotification) error {
+			calls = append(calls, "second")
+			return nil
+		})
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestNotification](first),
+				mediator.NewNotificationHandlerDefinition[TestNotification](second),
+			),
+			mediator.WithPublishStrategy(TestReversePublishStrategy{handlers: &handlers}),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		if assert.Len(t, handlers, 2) {
+			assert.Same(t, first, handlers[0])
+			assert.IsType(t, second, handlers[1])
+		}
+		assert.Equal(t, []string{"second", "first"}, calls)
+	})
+
+	t.Run("should launch a handler which was not resolved by the container", func(t *testing.T) {
+		other := &TestNotificationHandler2{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{})),
+			mediator.WithPublishStrategy(TestReplacePublishStrategy{handler: other}),
+		)
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		assert.True(t, other.Executed)
+	})
+	t.Run("should tell apart the function handlers built by the same helper", func(t *testing.T) {
+		handlerErr := errors.New("first failed")
+		var definitions []mediator.NotificationHandlerDefinition
+		for _, err := range []error{handlerErr, nil} {
+			definitions = append(definitions, mediator.NewNotificationHandlerDefinition[TestNotification](newTestFuncNotificationHandler(err)))
+		}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(definitions...),
+			mediator.WithPublishStrategy(mediator.NewParallelPublishStrategy()),
+		)
+
+		for i := 0; i < 20; i++ {
+			err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+			var publishErr *mediator.PublishError
+			if assert.ErrorAs(t, err, &publishErr) && assert.Len(t, publishErr.Errors, 1) {
+				assert.Equal(t, 0, publishErr.Errors[0].Index)
+				assert.ErrorIs(t, publishErr.Errors[0], handlerErr)
+			}
+		}
+	})
 }
@@ -0,0 +1,78 @@
+package mediator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HandlerError is the error of a single notification handler
+type HandlerError struct {
+	// Index is the position of the handler in the handlers of the notification
+	Index int
+	// HandlerType is the type of the handler
+	HandlerType reflect.Type
+	// Duration is the time spent in the handler before it failed
+	Duration time.Duration
+	// Err is the error returned by the handler
+	Err error
+}
+
+// HandlerName returns the name of the handler type
+func (e *HandlerError) HandlerName() string {
+	if e.HandlerType == nil {
+		return "<nil>"
+	}
+	return e.HandlerType.String()
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler #%d %s failed after %s: %v", e.Index, e.HandlerName(), e.Duration, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// PublishError is the error returned when one or more notification handlers failed
+// It records every failing handler, ordered by handler index
+type PublishError struct {
+	Errors []*HandlerError
+}
+
+func (e *PublishError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, handlerErr := range e.Errors {
+		messages = append(messages, handlerErr.Error())
+	}
+	return fmt.Sprintf("%d notification handler(s) failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the error of every failing handler, so errors.Is and errors.As can inspect them
+func (e *PublishError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, handlerErr := range e.Errors {
+		errs = append(errs, handlerErr)
+	}
+	return errs
+}
+
+func newHandlerError(index int, handler interface{}, duration time.Duration, err error) *HandlerError {
+	return &HandlerError{
+		Index:       index,
+		HandlerType: reflect.TypeOf(handler),
+		Duration:    duration,
+		Err:         err,
+	}
+}
+
+// newPublishError returns a PublishError for the given failures, or nil if there are none
+func newPublishError(handlerErrors []*HandlerError) error {
+	if len(handlerErrors) == 0 {
+		return nil
+	}
+	return &PublishError{
+		Errors: handlerErrors,
+	}
+}
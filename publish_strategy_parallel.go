@@ -2,40 +2,111 @@ package mediator
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 )
 
+// ParallelPublishStrategyOptions configures the parallel publish strategy
+type ParallelPublishStrategyOptions struct {
+	// MaxConcurrency is the maximum number of handlers executed at the same time, 0 means no limit
+	MaxConcurrency int
+	// FailFast cancels the context of the handlers at the first error and stops launching the remaining ones
+	FailFast bool
+}
+
+// WithMaxConcurrency limits the number of handlers executed at the same time
+func WithMaxConcurrency(maxConcurrency int) func(*ParallelPublishStrategyOptions) {
+	return func(options *ParallelPublishStrategyOptions) {
+		options.MaxConcurrency = maxConcurrency
+	}
+}
+
+// WithFailFast cancels the remaining handlers at the first error
+func WithFailFast() func(*ParallelPublishStrategyOptions) {
+	return func(options *ParallelPublishStrategyOptions) {
+		options.FailFast = true
+	}
+}
+
 type parallelPublishStrategy struct {
+	maxConcurrency int
+	failFast       bool
 }
 
 func (p parallelPublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher LaunchHandler) error {
-	errChan := make(chan error, len(handlers))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var semaphore chan struct{}
+	if p.maxConcurrency > 0 && p.maxConcurrency < len(handlers) {
+		semaphore = make(chan struct{}, p.maxConcurrency)
+	}
+	// stopped is only closed on fail fast, a nil channel never stops the launch loop
+	var stopped <-chan struct{}
+	if p.failFast {
+		stopped = ctx.Done()
+	}
+
+	handlerErrors := make([]*HandlerError, len(handlers))
 	var wg sync.WaitGroup
 
-	for _, handler := range handlers {
-		go func(handler interface{}) {
-			wg.Add(1)
+launch:
+	for i, handler := range handlers {
+		if semaphore != nil {
+			select {
+			case semaphore <- struct{}{}:
+			case <-stopped:
+				break launch
+			}
+		}
+		if p.failFast && ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, handler interface{}) {
 			defer wg.Done()
-			errChan <- launcher(ctx, handler)
-		}(handler)
+			if semaphore != nil {
+				defer func() { <-semaphore }()
+			}
+			start := time.Now()
+			// The handlers run at the same time, there is nothing to stop but it is not a failure
+			if err := launcher(ctx, handler); err != nil && !errors.Is(err, ErrStopPropagation) {
+				handlerErrors[index] = newHandlerError(index, handler, time.Since(start), err)
+				if p.failFast {
+					cancel()
+				}
+			}
+		}(i, handler)
 	}
 
 	wg.Wait()
 
-	for i := 0; i < len(handlers); i++ {
-		if err := <-errChan; err != nil {
-			return err
+	var failures []*HandlerError
+	for _, handlerErr := range handlerErrors {
+		if handlerErr != nil {
+			failures = append(failures, handlerErr)
 		}
 	}
-	return nil
+	return newPublishError(failures)
 }
 
-func NewParallelPublishStrategy() PublishStrategy {
-	return parallelPublishStrategy{}
+// NewParallelPublishStrategy creates a strategy executing the handlers in parallel
+// It waits for all launched handlers and returns a PublishError reporting the failures ordered by handler index
+func NewParallelPublishStrategy(optFns ...func(*ParallelPublishStrategyOptions)) PublishStrategy {
+	options := &ParallelPublishStrategyOptions{}
+	for _, optFn := range optFns {
+		optFn(options)
+	}
+	return parallelPublishStrategy{
+		maxConcurrency: options.MaxConcurrency,
+		failFast:       options.FailFast,
+	}
 }
 
-func WithParallelPublishStrategy() func(*PublishOptions) {
+func WithParallelPublishStrategy(optFns ...func(*ParallelPublishStrategyOptions)) func(*PublishOptions) {
 	return func(options *PublishOptions) {
-		options.PublishStrategy = NewParallelPublishStrategy()
+		options.PublishStrategy = NewParallelPublishStrategy(optFns...)
 	}
 }
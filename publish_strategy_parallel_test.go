@@ -5,7 +5,9 @@ import (
 	"errors"
 	"github.com/Oleexo/mediator-go"
 	"github.com/stretchr/testify/assert"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type parallelHandler interface {
@@ -75,4 +77,107 @@ func TestParallelPublishStrategy(t *testing.T) {
 			assert.True(t, handler.(parallelHandler).Executed())
 		}
 	})
+	t.Run("errors are reported in handler order", func(t *testing.T) {
+		strategy := mediator.NewParallelPublishStrategy()
+		err1 := errors.New("first error")
+		err2 := errors.New("second error")
+		handlers := []interface{}{
+			newParallelHandler(nil),
+			newParallelHandler(err1),
+			newParallelHandler(err2),
+		}
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				if handler == handlers[1] {
+					time.Sleep(10 * time.Millisecond)
+				}
+				return handler.(parallelHandler).Execute()
+			})
+
+		var publishErr *mediator.PublishError
+		assert.ErrorAs(t, result, &publishErr)
+		assert.Len(t, publishErr.Errors, 2)
+		assert.ErrorIs(t, publishErr.Errors[0], err1)
+		assert.ErrorIs(t, publishErr.Errors[1], err2)
+	})
+
+	t.Run("max concurrency limits the running handlers", func(t *testing.T) {
+		strategy := mediator.NewParallelPublishStrategy(mediator.WithMaxConcurrency(2))
+		handlers := make([]interface{}, 10)
+		for i := range handlers {
+			handlers[i] = newParallelHandler(nil)
+		}
+		var running, maxRunning atomic.Int32
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				current := running.Add(1)
+				defer running.Add(-1)
+				for {
+					observed := maxRunning.Load()
+					if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				return handler.(parallelHandler).Execute()
+			})
+
+		assert.NoError(t, result)
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+		for _, handler := range handlers {
+			assert.True(t, handler.(parallelHandler).Executed())
+		}
+	})
+
+	t.Run("fail fast cancels the other handlers", func(t *testing.T) {
+		strategy := mediator.NewParallelPublishStrategy(mediator.WithFailFast())
+		handlerErr := errors.New("error")
+		handlers := []interface{}{
+			newParallelHandler(handlerErr),
+			newParallelHandler(nil),
+		}
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				if err := handler.(parallelHandler).Execute(); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			})
+
+		var publishErr *mediator.PublishError
+		assert.ErrorAs(t, result, &publishErr)
+		assert.ErrorIs(t, publishErr.Errors[0], handlerErr)
+		assert.ErrorIs(t, result, context.Canceled)
+	})
+
+	t.Run("fail fast stops launching the remaining handlers", func(t *testing.T) {
+		strategy := mediator.NewParallelPublishStrategy(mediator.WithMaxConcurrency(1), mediator.WithFailFast())
+		handlers := []interface{}{
+			newParallelHandler(errors.New("error")),
+			newParallelHandler(nil),
+			newParallelHandler(nil),
+		}
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				return handler.(parallelHandler).Execute()
+			})
+
+		assert.Error(t, result)
+		assert.True(t, handlers[0].(parallelHandler).Executed())
+		assert.False(t, handlers[1].(parallelHandler).Executed())
+		assert.False(t, handlers[2].(parallelHandler).Executed())
+	})
 }
@@ -0,0 +1,37 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type runAllPublishStrategy struct {
+}
+
+func (r runAllPublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher LaunchHandler) error {
+	var handlerErrors []*HandlerError
+	for i, handler := range handlers {
+		start := time.Now()
+		err := launcher(ctx, handler)
+		if errors.Is(err, ErrStopPropagation) {
+			break
+		}
+		if err != nil {
+			handlerErrors = append(handlerErrors, newHandlerError(i, handler, time.Since(start), err))
+		}
+	}
+	return newPublishError(handlerErrors)
+}
+
+// NewRunAllPublishStrategy creates a strategy executing the handlers one by one,
+// it runs all of them even when some fail and then returns a PublishError reporting every failure
+func NewRunAllPublishStrategy() PublishStrategy {
+	return runAllPublishStrategy{}
+}
+
+func WithRunAllPublishStrategy() func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.PublishStrategy = NewRunAllPublishStrategy()
+	}
+}
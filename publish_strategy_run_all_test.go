@@ -0,0 +1,90 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type TestFailingNotificationHandler struct {
+	err error
+}
+
+func (h *TestFailingNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	return h.err
+}
+
+func TestRunAllPublishStrategy(t *testing.T) {
+	t.Run("no error will run all handlers", func(t *testing.T) {
+		strategy := mediator.NewRunAllPublishStrategy()
+		handlers := []interface{}{
+			newSynchronousHandler(nil),
+			newSynchronousHandler(nil),
+		}
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				return handler.(synchronousHandler).Execute()
+			})
+
+		assert.NoError(t, result)
+		for _, handler := range handlers {
+			assert.True(t, handler.(synchronousHandler).Executed())
+		}
+	})
+
+	t.Run("errors will not stop the execution and are all reported", func(t *testing.T) {
+		strategy := mediator.NewRunAllPublishStrategy()
+		err1 := errors.New("first error")
+		err2 := errors.New("second error")
+		handlers := []interface{}{
+			newSynchronousHandler(err1),
+			newSynchronousHandler(nil),
+			newSynchronousHandler(err2),
+		}
+
+		result := strategy.Execute(context.Background(),
+			handlers,
+			func(ctx context.Context, handler interface{}) error {
+				return handler.(synchronousHandler).Execute()
+			})
+
+		for _, handler := range handlers {
+			assert.True(t, handler.(synchronousHandler).Executed())
+		}
+		assert.ErrorIs(t, result, err1)
+		assert.ErrorIs(t, result, err2)
+
+		var publishErr *mediator.PublishError
+		assert.ErrorAs(t, result, &publishErr)
+		assert.Len(t, publishErr.Errors, 2)
+		assert.Equal(t, 0, publishErr.Errors[0].Index)
+		assert.Equal(t, 2, publishErr.Errors[1].Index)
+		assert.Equal(t, reflect.TypeOf(handlers[2]), publishErr.Errors[1].HandlerType)
+	})
+
+	t.Run("should report the failing handler through the container", func(t *testing.T) {
+		handlerErr := errors.New("smtp unavailable")
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestFailingNotificationHandler{err: handlerErr}),
+				mediator.NewNotificationHandlerDefinition[TestNotification](handler),
+			),
+			mediator.WithRunAllPublishStrategy(),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.True(t, handler.Executed)
+
+		var handlerError *mediator.HandlerError
+		assert.ErrorAs(t, err, &handlerError)
+		assert.Equal(t, "*mediator_test.TestFailingNotificationHandler", handlerError.HandlerName())
+		assert.ErrorIs(t, err, handlerErr)
+		assert.ErrorContains(t, err, "handler #0 *mediator_test.TestFailingNotificationHandler failed after")
+	})
+}
@@ -1,6 +1,9 @@
 package mediator
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 type synchronousPublishStrategy struct {
 }
@@ -8,6 +11,9 @@ type synchronousPublishStrategy struct {
 func (s synchronousPublishStrategy) Execute(ctx context.Context, handlers []interface{}, launcher LaunchHandler) error {
 	for _, handler := range handlers {
 		err := launcher(ctx, handler)
+		if errors.Is(err, ErrStopPropagation) {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -17,29 +17,35 @@ func NewPublisher(container PublishContainer) Publisher {
 
 }
 
-func (s publisher) Publish(ctx context.Context, notification interface{}) error {
-	handlers := s.container.resolve(notification)
-	if handlers == nil {
-		return nil
+func (s publisher) Publish(ctx context.Context, notification interface{}, optFns ...func(*CallOptions)) error {
+	call, ctx := newCall(ctx, optFns)
+	resolved := s.container.resolveNotification(notification)
+	if resolved == nil {
+		return call.endNotification(nil, s.container.publishUnhandled(ctx, notification))
 	}
 
-	return s.container.getStrategy().Execute(ctx, handlers, func(handlerCtx context.Context, handler interface{}) error {
-		handlerMethod := reflect.ValueOf(handler).
-			MethodByName("Handle")
-		if !handlerMethod.IsValid() {
-			return fmt.Errorf("handler for notification %T is not a NotificationHandler", notification)
-		}
-		// Create a slice of reflect.Value with ctx and notification as arguments
-		args := []reflect.Value{reflect.ValueOf(handlerCtx), reflect.ValueOf(notification)}
-
-		// Call the method with ctx and notification as arguments and get the returned error
-		result := handlerMethod.Call(args)
-
-		// The first (and only) return value of the Handle method is the error
-		methodResult := result[0].Interface()
-		if methodResult != nil {
-			return methodResult.(error)
-		}
-		return nil
-	})
+	err := s.container.executeWithBehaviors(ctx, notification, resolved, call.callOptions())
+	return call.endNotification(resolved.handlers, err)
+}
+
+// invokeNotificationHandler calls the Handle method of a notification handler with reflection
+// It is only used for the definitions which are not created by NewNotificationHandlerDefinition
+func invokeNotificationHandler(ctx context.Context, handler interface{}, notification interface{}) error {
+	handlerMethod := reflect.ValueOf(handler).
+		MethodByName("Handle")
+	if !handlerMethod.IsValid() {
+		return fmt.Errorf("%w for notification %T: %T has no Handle method", ErrInvalidHandler, notification, handler)
+	}
+	// Create a slice of reflect.Value with ctx and notification as arguments
+	args := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(notification)}
+
+	// Call the method with ctx and notification as arguments and get the returned error
+	result := handlerMethod.Call(args)
+
+	// The first (and only) return value of the Handle method is the error
+	methodResult := result[0].Interface()
+	if methodResult != nil {
+		return methodResult.(error)
+	}
+	return nil
 }
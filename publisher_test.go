@@ -4,9 +4,22 @@ import (
 	"context"
 	"github.com/Oleexo/mediator-go"
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
+type testCustomNotificationHandlerDefinition struct {
+	handler interface{}
+}
+
+func (d testCustomNotificationHandlerDefinition) NotificationType() reflect.Type {
+	return reflect.TypeOf(TestNotification{})
+}
+
+func (d testCustomNotificationHandlerDefinition) Handler() interface{} {
+	return d.handler
+}
+
 func TestPublisher(t *testing.T) {
 	t.Run("With no handlers", func(t *testing.T) {
 		container := mediator.NewPublishContainer()
@@ -49,4 +62,41 @@ func TestPublisher(t *testing.T) {
 		assert.True(t, handler2.Executed)
 	})
 
+	t.Run("With a handler of a custom definition", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(testCustomNotificationHandlerDefinition{handler: handler}),
+		)
+		publisher := mediator.NewPublisher(container)
+
+		err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.True(t, handler.Executed)
+	})
+
+	t.Run("With an invalid handler of a custom definition", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(testCustomNotificationHandlerDefinition{handler: struct{}{}}),
+		)
+		publisher := mediator.NewPublisher(container)
+
+		err := publisher.Publish(context.Background(), TestNotification{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+	})
+
+	t.Run("should publish to a function handler", func(t *testing.T) {
+		var received []string
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](
+				mediator.NotificationHandlerFunc[TestNotification](func(ctx context.Context, notification TestNotification) error {
+					received = append(received, notification.Value)
+					return nil
+				}))),
+		)
+		assert.NoError(t, container.Validate())
+
+		err := mediator.NewPublisher(container).Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test"}, received)
+	})
 }
@@ -0,0 +1,48 @@
+package mediator
+
+// Unregister removes a handler or a behavior registered on a live container
+// The calls in flight finish with the handlers and behaviors they started with.
+// Calling it more than once, or after the registration was replaced, has no effect
+type Unregister func()
+
+// registration is a handler or a behavior registered on a container, with the id identifying it
+type registration[T interface{}] struct {
+	id    uint64
+	value T
+}
+
+// registrations is a list of registrations, it is never modified in place
+// so the snapshots of a container can share it
+type registrations[T interface{}] []registration[T]
+
+func (r registrations[T]) with(id uint64, value T) registrations[T] {
+	result := make(registrations[T], len(r), len(r)+1)
+	copy(result, r)
+	return append(result, registration[T]{id: id, value: value})
+}
+
+// without returns the list without the registration of the id, and whether it was found
+func (r registrations[T]) without(id uint64) (registrations[T], bool) {
+	return r.filter(func(registration registration[T]) bool {
+		return registration.id != id
+	})
+}
+
+// filter returns the registrations matching the predicate, and whether some were removed
+func (r registrations[T]) filter(keep func(registration[T]) bool) (registrations[T], bool) {
+	result := make(registrations[T], 0, len(r))
+	for _, registration := range r {
+		if keep(registration) {
+			result = append(result, registration)
+		}
+	}
+	return result, len(result) != len(r)
+}
+
+func (r registrations[T]) values() []T {
+	values := make([]T, 0, len(r))
+	for _, registration := range r {
+		values = append(values, registration.value)
+	}
+	return values
+}
@@ -0,0 +1,195 @@
+package mediator_test
+
+import (
+	"context"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+type TestBlockingRequestHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (t TestBlockingRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	close(t.entered)
+	<-t.release
+	return "old", nil
+}
+
+type TestConstantRequestHandler struct {
+	response string
+}
+
+func (t TestConstantRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	return t.response, nil
+}
+
+func TestSendContainerRegistration(t *testing.T) {
+	t.Run("should register and unregister a request handler", func(t *testing.T) {
+		container := mediator.NewSendContainer()
+
+		unregister := container.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{}))
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+
+		unregister()
+		unregister()
+		_, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should replace the handler of a request type", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestConstantRequestHandler{response: "initial"})),
+		)
+
+		unregisterFirst := container.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestConstantRequestHandler{response: "first"}))
+		unregisterSecond := container.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestConstantRequestHandler{response: "second"}))
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "second", response)
+		assert.NoError(t, container.Validate())
+
+		// The replaced registration is gone, unregistering it has no effect
+		unregisterFirst()
+		response, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "second", response)
+
+		unregisterSecond()
+		_, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should finish the calls in flight on the replaced handler", func(t *testing.T) {
+		blocking := TestBlockingRequestHandler{entered: make(chan struct{}), release: make(chan struct{})}
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](blocking)),
+		)
+
+		inFlight := make(chan string)
+		go func() {
+			response, _ := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+			inFlight <- response
+		}()
+		<-blocking.entered
+
+		container.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestConstantRequestHandler{response: "new"}))
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "new", response)
+
+		close(blocking.release)
+		assert.Equal(t, "old", <-inFlight)
+	})
+
+	t.Run("should register and unregister a pipeline behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+		)
+
+		unregister := container.RegisterPipelineBehavior(mediator.AdaptPipelineBehavior(TestPipelineBehavior{}))
+		response, err := mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response)
+
+		unregister()
+		response, err = mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+	})
+
+	t.Run("should register a stream request handler and a stream pipeline behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer()
+
+		unregister := container.RegisterStreamRequestHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{}))
+		container.RegisterStreamPipelineBehavior(TestLimitStreamPipelineBehavior{Limit: 2})
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 3})
+		assert.NoError(t, err)
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, items)
+
+		unregister()
+		_, err = mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 3})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should register handlers while requests are sent", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		for i := 0; i < 100; i++ {
+			unregister := container.RegisterPipelineBehavior(TestContextPipelineBehavior{})
+			container.RegisterRequestHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{}))
+			unregister()
+		}
+		wg.Wait()
+	})
+}
+
+func TestPublishContainerRegistration(t *testing.T) {
+	t.Run("should register and unregister a notification handler", func(t *testing.T) {
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer()
+
+		unregister := container.RegisterNotificationHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler))
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.True(t, handler.Executed)
+
+		unregister()
+		handler.Executed = false
+		err = mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.False(t, handler.Executed)
+	})
+
+	t.Run("should run the registered handlers after the existing handlers of the same order", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "first", calls: &calls})),
+		)
+
+		container.RegisterNotificationHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "second", calls: &calls}))
+		container.RegisterNotificationHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestOrderedNotificationHandler{name: "early", calls: &calls},
+			mediator.WithHandlerOrder(-1)))
+		err := mediator.NewPublisher(container).Publish(context.Background(), TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"early", "first", "second"}, calls)
+	})
+
+	t.Run("should register and unregister notification behaviors", func(t *testing.T) {
+		var mu sync.Mutex
+		var calls []string
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{})),
+		)
+
+		unregister := container.RegisterNotificationHandlerBehavior(TestRecordNotificationHandlerBehavior{mu: &mu, calls: &calls})
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"*mediator_test.TestNotificationHandler"}, calls)
+
+		unregister()
+		err = mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Len(t, calls, 1)
+	})
+}
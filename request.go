@@ -17,3 +17,11 @@ type Request[TResponse interface{}] interface {
 type RequestHandler[TRequest Request[TResponse], TResponse interface{}] interface {
 	Handle(ctx context.Context, request TRequest) (TResponse, error)
 }
+
+// HandlerFunc is a function handling a request, it is a RequestHandler
+// It lets a function be given to NewRequestHandlerDefinition without declaring a handler type
+type HandlerFunc[TRequest Request[TResponse], TResponse interface{}] func(ctx context.Context, request TRequest) (TResponse, error)
+
+func (f HandlerFunc[TRequest, TResponse]) Handle(ctx context.Context, request TRequest) (TResponse, error) {
+	return f(ctx, request)
+}
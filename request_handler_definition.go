@@ -1,6 +1,10 @@
 package mediator
 
-import "reflect"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
 
 // RequestHandlerDefinition is a marker interface for request handler definitions
 // It is used to define a request handler and its associated request type
@@ -11,18 +15,20 @@ type RequestHandlerDefinition interface {
 
 // NewRequestHandlerDefinition creates a new request handler definition
 func NewRequestHandlerDefinition[TRequest Request[TResponse], TResponse interface{}](handler RequestHandler[TRequest, TResponse]) RequestHandlerDefinition {
-	var request TRequest
-	requestType := reflect.TypeOf(request)
+	// TypeOf a pointer keeps the interface types, which have no dynamic type
+	requestType := reflect.TypeOf((*TRequest)(nil)).Elem()
 
 	return &TypedRequestHandlerDefinition[TRequest, TResponse]{
-		requestType: requestType,
-		handler:     handler,
+		requestType:  requestType,
+		responseType: reflect.TypeOf((*TResponse)(nil)).Elem(),
+		handler:      handler,
 	}
 }
 
 type TypedRequestHandlerDefinition[TRequest Request[TResponse], TResponse interface{}] struct {
-	requestType reflect.Type
-	handler     RequestHandler[TRequest, TResponse]
+	requestType  reflect.Type
+	responseType reflect.Type
+	handler      RequestHandler[TRequest, TResponse]
 }
 
 func (t TypedRequestHandlerDefinition[TRequest, TResponse]) Handler() interface{} {
@@ -32,3 +38,68 @@ func (t TypedRequestHandlerDefinition[TRequest, TResponse]) Handler() interface{
 func (t TypedRequestHandlerDefinition[TRequest, TResponse]) RequestType() reflect.Type {
 	return t.requestType
 }
+
+// ResponseType returns the type of the responses of the handler
+func (t TypedRequestHandlerDefinition[TRequest, TResponse]) ResponseType() reflect.Type {
+	return t.responseType
+}
+
+func (t TypedRequestHandlerDefinition[TRequest, TResponse]) invokeHandler(ctx context.Context, request BaseRequest) (interface{}, error) {
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return nil, fmt.Errorf("request %T is not a %s", request, t.requestType)
+	}
+	return t.handler.Handle(ctx, typedRequest)
+}
+
+// requestHandlerInvoker is implemented by the definitions calling their handler without reflection
+type requestHandlerInvoker interface {
+	invokeHandler(ctx context.Context, request BaseRequest) (interface{}, error)
+}
+
+// responseTypeDefinition is implemented by the definitions knowing the response type of their handler
+type responseTypeDefinition interface {
+	ResponseType() reflect.Type
+}
+
+// requestHandlerEntry is a registered request handler with the function invoking it
+type requestHandlerEntry struct {
+	requestType reflect.Type
+	handler     interface{}
+	invoke      NextFunc
+	// responseType is the type of the responses of the handler, nil when the definition does not know it
+	responseType reflect.Type
+	// pipeline is the pipeline behaviors of the container chained in front of invoke,
+	// it is compiled once when the handler is registered
+	pipeline NextFunc
+	// behaviors are the pipeline behaviors of the container, for the calls changing them
+	behaviors []ContextPipelineBehavior
+}
+
+func newRequestHandlerEntry(definition RequestHandlerDefinition) *requestHandlerEntry {
+	handler := definition.Handler()
+	entry := &requestHandlerEntry{
+		requestType: definition.RequestType(),
+		handler:     handler,
+	}
+	if typed, ok := definition.(responseTypeDefinition); ok {
+		entry.responseType = typed.ResponseType()
+	}
+	var invoke NextFunc
+	if invoker, ok := definition.(requestHandlerInvoker); ok {
+		invoke = invoker.invokeHandler
+	} else {
+		// The definition is not generic, the handler can only be called with reflection
+		invoke = func(ctx context.Context, request BaseRequest) (interface{}, error) {
+			return invokeRequestHandler(ctx, handler, request)
+		}
+	}
+	entry.invoke = func(ctx context.Context, request BaseRequest) (interface{}, error) {
+		response, err := invoke(ctx, request)
+		if err != nil {
+			recordHandlerError(ctx, err)
+		}
+		return response, err
+	}
+	return entry
+}
@@ -0,0 +1,134 @@
+package mediator
+
+import "context"
+
+// RequestPreProcessor is a marker interface for request pre-processors
+// A request pre-processor is executed before the handler of its request type,
+// an error returned by it stops the request before the handler is called
+type RequestPreProcessor[TRequest BaseRequest] interface {
+	Process(ctx context.Context, request TRequest) error
+}
+
+// RequestPostProcessor is a marker interface for request post-processors
+// A request post-processor is executed after the handler of its request type succeeded
+type RequestPostProcessor[TRequest Request[TResponse], TResponse interface{}] interface {
+	Process(ctx context.Context, request TRequest, response TResponse) error
+}
+
+// RequestExceptionHandler is a marker interface for request exception handlers
+// A request exception handler inspects the error returned while handling a request of its type.
+// It returns the same error to rethrow it, another error to map it,
+// or nil to mark it handled, the returned response is then used as the response of the request
+type RequestExceptionHandler[TRequest Request[TResponse], TResponse interface{}] interface {
+	Handle(ctx context.Context, request TRequest, err error) (TResponse, error)
+}
+
+// WithRequestPreProcessor adds a request pre-processor to the container
+// Pre-processors run in their registration order, inside the pipeline behaviors
+func WithRequestPreProcessor[TRequest BaseRequest](preProcessor RequestPreProcessor[TRequest]) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.RequestPreProcessors = append(options.RequestPreProcessors, requestPreProcessorBehavior[TRequest]{
+			preProcessor: preProcessor,
+		})
+	}
+}
+
+// WithRequestPostProcessor adds a request post-processor to the container
+// Post-processors run in their registration order, inside the pipeline behaviors
+func WithRequestPostProcessor[TRequest Request[TResponse], TResponse interface{}](postProcessor RequestPostProcessor[TRequest, TResponse]) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.RequestPostProcessors = append(options.RequestPostProcessors, requestPostProcessorBehavior[TRequest, TResponse]{
+			postProcessor: postProcessor,
+		})
+	}
+}
+
+// WithRequestExceptionHandler adds a request exception handler to the container
+// Exception handlers see the errors of the pre-processors, the handler and the post-processors.
+// They are tried in their registration order until one of them handles the error
+func WithRequestExceptionHandler[TRequest Request[TResponse], TResponse interface{}](exceptionHandler RequestExceptionHandler[TRequest, TResponse]) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.RequestExceptionHandlers = append(options.RequestExceptionHandlers, requestExceptionHandlerBehavior[TRequest, TResponse]{
+			exceptionHandler: exceptionHandler,
+		})
+	}
+}
+
+// requestProcessorBehaviors returns the behaviors executing the processors and exception handlers,
+// from the outermost to the innermost
+func requestProcessorBehaviors(options *SendContainerOptions) []ContextPipelineBehavior {
+	var behaviors []ContextPipelineBehavior
+	for i := len(options.RequestExceptionHandlers) - 1; i >= 0; i-- {
+		behaviors = append(behaviors, options.RequestExceptionHandlers[i])
+	}
+	behaviors = append(behaviors, options.RequestPreProcessors...)
+	for i := len(options.RequestPostProcessors) - 1; i >= 0; i-- {
+		behaviors = append(behaviors, options.RequestPostProcessors[i])
+	}
+	return behaviors
+}
+
+type requestPreProcessorBehavior[TRequest BaseRequest] struct {
+	preProcessor RequestPreProcessor[TRequest]
+}
+
+func (r requestPreProcessorBehavior[TRequest]) unwrap() interface{} {
+	return r.preProcessor
+}
+
+func (r requestPreProcessorBehavior[TRequest]) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	if typedRequest, ok := request.(TRequest); ok {
+		if err := r.preProcessor.Process(ctx, typedRequest); err != nil {
+			return nil, err
+		}
+	}
+	return next(ctx, request)
+}
+
+type requestPostProcessorBehavior[TRequest Request[TResponse], TResponse interface{}] struct {
+	postProcessor RequestPostProcessor[TRequest, TResponse]
+}
+
+func (r requestPostProcessorBehavior[TRequest, TResponse]) unwrap() interface{} {
+	return r.postProcessor
+}
+
+func (r requestPostProcessorBehavior[TRequest, TResponse]) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	response, err := next(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return response, nil
+	}
+	typedResponse, _ := response.(TResponse)
+	if err := r.postProcessor.Process(ctx, typedRequest, typedResponse); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+type requestExceptionHandlerBehavior[TRequest Request[TResponse], TResponse interface{}] struct {
+	exceptionHandler RequestExceptionHandler[TRequest, TResponse]
+}
+
+func (r requestExceptionHandlerBehavior[TRequest, TResponse]) unwrap() interface{} {
+	return r.exceptionHandler
+}
+
+func (r requestExceptionHandlerBehavior[TRequest, TResponse]) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	response, err := next(ctx, request)
+	if err == nil {
+		return response, nil
+	}
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return response, err
+	}
+	handledResponse, err := r.exceptionHandler.Handle(ctx, typedRequest, err)
+	if err != nil {
+		return response, err
+	}
+	return handledResponse, nil
+}
@@ -0,0 +1,162 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var errTestNotFound = errors.New("not found")
+
+type TestFailingRequestHandler struct {
+	err error
+}
+
+func (t TestFailingRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	return "", t.err
+}
+
+type TestPreProcessor struct {
+	calls *[]string
+	err   error
+}
+
+func (t TestPreProcessor) Process(ctx context.Context, request *TestRequest) error {
+	*t.calls = append(*t.calls, "pre:"+request.Value)
+	return t.err
+}
+
+type TestPostProcessor struct {
+	name  string
+	calls *[]string
+}
+
+func (t TestPostProcessor) Process(ctx context.Context, request *TestRequest, response string) error {
+	*t.calls = append(*t.calls, t.name+":"+response)
+	return nil
+}
+
+type TestNotFoundExceptionHandler struct {
+}
+
+func (t TestNotFoundExceptionHandler) Handle(ctx context.Context, request *TestRequest, err error) (string, error) {
+	if errors.Is(err, errTestNotFound) {
+		return "default", nil
+	}
+	return "", err
+}
+
+type TestSentinelExceptionHandler struct {
+}
+
+func (t TestSentinelExceptionHandler) Handle(ctx context.Context, request *TestRequest, err error) (string, error) {
+	if err == errTestNotFound {
+		return "sentinel", nil
+	}
+	return "", err
+}
+
+type TestMappingExceptionHandler struct {
+	calls *[]string
+}
+
+func (t TestMappingExceptionHandler) Handle(ctx context.Context, request *TestRequest, err error) (string, error) {
+	*t.calls = append(*t.calls, "map:"+err.Error())
+	return "", errTestNotFound
+}
+
+func TestRequestProcessors(t *testing.T) {
+	t.Run("should run the processors around the handler", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithRequestPostProcessor[*TestRequest, string](TestPostProcessor{name: "post1", calls: &calls}),
+			mediator.WithRequestPreProcessor[*TestRequest](TestPreProcessor{calls: &calls}),
+			mediator.WithRequestPostProcessor[*TestRequest, string](TestPostProcessor{name: "post2", calls: &calls}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test", response)
+		assert.Equal(t, []string{"pre:test", "post1:test", "post2:test"}, calls)
+	})
+
+	t.Run("should stop the request when a pre-processor fails", func(t *testing.T) {
+		var calls []string
+		preErr := errors.New("invalid")
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithRequestPreProcessor[*TestRequest](TestPreProcessor{calls: &calls, err: preErr}),
+			mediator.WithRequestPostProcessor[*TestRequest, string](TestPostProcessor{name: "post", calls: &calls}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, preErr)
+		assert.Equal(t, []string{"pre:test"}, calls)
+	})
+
+	t.Run("should substitute the response of a handled error", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: errTestNotFound})),
+			mediator.WithRequestExceptionHandler[*TestRequest, string](TestNotFoundExceptionHandler{}),
+		)
+		sender := mediator.NewSender(container)
+
+		response, err := sender.Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "default", response)
+	})
+
+	t.Run("should give the error of the handler as is to the exception handlers", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: errTestNotFound})),
+			mediator.WithRequestExceptionHandler[*TestRequest, string](TestSentinelExceptionHandler{}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "sentinel", response)
+	})
+
+	t.Run("should rethrow an unhandled error", func(t *testing.T) {
+		handlerErr := errors.New("database down")
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: handlerErr})),
+			mediator.WithRequestExceptionHandler[*TestRequest, string](TestNotFoundExceptionHandler{}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, handlerErr)
+	})
+
+	t.Run("should pass a mapped error to the next exception handler", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: errors.New("no rows")})),
+			mediator.WithRequestExceptionHandler[*TestRequest, string](TestMappingExceptionHandler{calls: &calls}),
+			mediator.WithRequestExceptionHandler[*TestRequest, string](TestNotFoundExceptionHandler{}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "default", response)
+		assert.Equal(t, []string{"map:no rows"}, calls)
+	})
+
+	t.Run("should not run for other request types", func(t *testing.T) {
+		var calls []string
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[TestOtherRequest, int](TestOtherRequestHandler{})),
+			mediator.WithRequestPreProcessor[*TestRequest](TestPreProcessor{calls: &calls}),
+			mediator.WithRequestPostProcessor[*TestRequest, string](TestPostProcessor{name: "post", calls: &calls}),
+		)
+
+		response, err := mediator.Send[TestOtherRequest, int](context.Background(), container, TestOtherRequest{Value: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response)
+		assert.Empty(t, calls)
+	})
+}
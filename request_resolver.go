@@ -0,0 +1,162 @@
+package mediator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// requestResolver finds the handler of a request type, it resolves the requests and the stream requests
+// A request is resolved, by priority:
+//   - to the handler registered for its exact type,
+//   - to the handler registered for its pointer type, or for its value type when it is a pointer,
+//   - to the handler registered for an interface it implements, when the interface resolution is enabled,
+//   - to the fallback handler.
+//
+// The second and third rules fail with an ambiguity error when several registrations match.
+// The resolutions are cached per request type.
+type requestResolver[TEntry interface{}] struct {
+	// kind names the requests in the errors, e.g. "request" or "stream request"
+	kind                string
+	handlers            map[reflect.Type]TEntry
+	interfaceTypes      []reflect.Type
+	interfaceResolution bool
+	// fallback is the entry of the fallback handler, it is used when hasFallback is set
+	fallback    TEntry
+	hasFallback bool
+	resolved    sync.Map
+}
+
+type requestResolution[TEntry interface{}] struct {
+	entry TEntry
+	// requestType is the type the handler is registered for
+	requestType reflect.Type
+	err         error
+}
+
+func newRequestResolver[TEntry interface{}](kind string, handlers map[reflect.Type]TEntry,
+	interfaceResolution bool) *requestResolver[TEntry] {
+	var interfaceTypes []reflect.Type
+	if interfaceResolution {
+		for requestType := range handlers {
+			if requestType.Kind() == reflect.Interface {
+				interfaceTypes = append(interfaceTypes, requestType)
+			}
+		}
+		// Keep the ambiguity errors stable whatever the map order is
+		sort.Slice(interfaceTypes, func(i, j int) bool {
+			return interfaceTypes[i].String() < interfaceTypes[j].String()
+		})
+	}
+	return &requestResolver[TEntry]{
+		kind:                kind,
+		handlers:            handlers,
+		interfaceTypes:      interfaceTypes,
+		interfaceResolution: interfaceResolution,
+	}
+}
+
+// withFallback resolves the requests without handler to the fallback entry
+func (r *requestResolver[TEntry]) withFallback(fallback TEntry) *requestResolver[TEntry] {
+	r.fallback = fallback
+	r.hasFallback = true
+	return r
+}
+
+// resolve returns the handler of the request and the request converted to the type the handler is registered for
+func (r *requestResolver[TEntry]) resolve(request BaseRequest) (TEntry, BaseRequest, error) {
+	requestType := reflect.TypeOf(request)
+	if entry, ok := r.handlers[requestType]; ok {
+		return entry, request, nil
+	}
+	if requestType == nil {
+		return *new(TEntry), nil, newResolveError(request, fmt.Errorf("%w for %s %T", ErrNoHandler, r.kind, request))
+	}
+
+	resolution, ok := r.resolved.Load(requestType)
+	if !ok {
+		resolution, _ = r.resolved.LoadOrStore(requestType, r.resolveType(requestType))
+	}
+	result := resolution.(*requestResolution[TEntry])
+	if result.err != nil {
+		return *new(TEntry), nil, result.err
+	}
+	converted, err := convertRequest(request, result.requestType)
+	if err != nil {
+		return *new(TEntry), nil, newResolveError(request, err)
+	}
+	return result.entry, converted, nil
+}
+
+func (r *requestResolver[TEntry]) resolveType(requestType reflect.Type) *requestResolution[TEntry] {
+	var candidates []reflect.Type
+	if requestType.Kind() == reflect.Pointer {
+		if _, ok := r.handlers[requestType.Elem()]; ok {
+			candidates = append(candidates, requestType.Elem())
+		}
+	} else if _, ok := r.handlers[reflect.PointerTo(requestType)]; ok {
+		candidates = append(candidates, reflect.PointerTo(requestType))
+	}
+	for _, interfaceType := range r.interfaceTypes {
+		if requestType.Implements(interfaceType) {
+			candidates = append(candidates, interfaceType)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if r.hasFallback {
+			return &requestResolution[TEntry]{
+				entry:       r.fallback,
+				requestType: baseRequestType,
+			}
+		}
+		return &requestResolution[TEntry]{
+			err: &DispatchError{
+				RequestType: requestType,
+				Stage:       StageResolve,
+				Err:         fmt.Errorf("%w for %s %s", ErrNoHandler, r.kind, requestType),
+			},
+		}
+	case 1:
+		return &requestResolution[TEntry]{
+			entry:       r.handlers[candidates[0]],
+			requestType: candidates[0],
+		}
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			names = append(names, candidate.String())
+		}
+		return &requestResolution[TEntry]{
+			err: &DispatchError{
+				RequestType: requestType,
+				Stage:       StageResolve,
+				Err: fmt.Errorf("%w for %s %s: registered for %s", ErrAmbiguousHandler, r.kind, requestType,
+					strings.Join(names, ", ")),
+			},
+		}
+	}
+}
+
+// convertRequest converts a request between its pointer and value types, interfaces are kept as is
+func convertRequest(request BaseRequest, requestType reflect.Type) (BaseRequest, error) {
+	value := reflect.ValueOf(request)
+	switch {
+	case requestType.Kind() == reflect.Interface:
+		return request, nil
+	case requestType == reflect.PointerTo(value.Type()):
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		return pointer.Interface().(BaseRequest), nil
+	case value.Kind() == reflect.Pointer && requestType == value.Type().Elem():
+		if value.IsNil() {
+			return nil, fmt.Errorf("request %T is nil", request)
+		}
+		return value.Elem().Interface().(BaseRequest), nil
+	default:
+		return request, nil
+	}
+}
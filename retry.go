@@ -0,0 +1,207 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Retryable is implemented by the errors which can be retried, e.g. the timeout of a remote call
+type Retryable interface {
+	Retryable() bool
+}
+
+// RetryableRequest is implemented by the requests retried by the retry pipeline behavior, e.g. the idempotent commands
+type RetryableRequest interface {
+	BaseRequest
+	Retryable() bool
+}
+
+// RetryClassifier reports whether an error can be retried
+type RetryClassifier func(err error) bool
+
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts, including the first one
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts
+	MaxBackoff time.Duration
+	// Multiplier is the growth of the delay after each retry
+	Multiplier float64
+	// Jitter is the fraction of the delay which is randomized, between 0 and 1
+	Jitter float64
+	// Classifier reports whether an error can be retried, IsRetryable by default
+	Classifier RetryClassifier
+	// AllRequests retries every request, not only the requests implementing RetryableRequest
+	AllRequests bool
+}
+
+// WithRetryMaxAttempts sets the maximum number of attempts, including the first one
+func WithRetryMaxAttempts(maxAttempts int) func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.MaxAttempts = maxAttempts
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry and the maximum delay between two attempts
+func WithRetryBackoff(initialBackoff time.Duration, maxBackoff time.Duration) func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.InitialBackoff = initialBackoff
+		options.MaxBackoff = maxBackoff
+	}
+}
+
+// WithRetryMultiplier sets the growth of the delay after each retry
+func WithRetryMultiplier(multiplier float64) func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.Multiplier = multiplier
+	}
+}
+
+// WithRetryJitter sets the fraction of the delay which is randomized, between 0 and 1
+func WithRetryJitter(jitter float64) func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.Jitter = jitter
+	}
+}
+
+// WithRetryClassifier sets the function reporting whether an error can be retried
+func WithRetryClassifier(classifier RetryClassifier) func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.Classifier = classifier
+	}
+}
+
+// WithRetryAllRequests retries every request, not only the requests implementing RetryableRequest
+func WithRetryAllRequests() func(*RetryOptions) {
+	return func(options *RetryOptions) {
+		options.AllRequests = true
+	}
+}
+
+// IsRetryable reports whether the error, or an error it wraps, implements Retryable and can be retried
+func IsRetryable(err error) bool {
+	var retryable Retryable
+	return errors.As(err, &retryable) && retryable.Retryable()
+}
+
+// NewRetryPipelineBehavior creates a pipeline behavior retrying the requests implementing RetryableRequest
+// The failed attempts are retried with an exponential backoff while their error is retryable,
+// there are 3 attempts with a backoff from 100ms to 10s by default.
+// It stops when the context is done or when its deadline would expire before the next attempt
+func NewRetryPipelineBehavior(optFns ...func(*RetryOptions)) ContextPipelineBehavior {
+	return &retryPipelineBehavior{
+		retrier: newRetrier(optFns),
+	}
+}
+
+type retryPipelineBehavior struct {
+	retrier *retrier
+}
+
+func (r *retryPipelineBehavior) Handle(ctx context.Context, request BaseRequest, next NextFunc) (interface{}, error) {
+	if !r.retrier.options.AllRequests {
+		if retryable, ok := request.(RetryableRequest); !ok || !retryable.Retryable() {
+			return next(ctx, request)
+		}
+	}
+
+	var response interface{}
+	err := r.retrier.do(ctx, func() error {
+		var err error
+		response, err = next(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// NewRetryNotificationHandlerBehavior creates a notification handler behavior retrying each failed handler
+// with the same rules as NewRetryPipelineBehavior, ErrStopPropagation is never retried
+func NewRetryNotificationHandlerBehavior(optFns ...func(*RetryOptions)) NotificationHandlerBehavior {
+	return &retryNotificationHandlerBehavior{
+		retrier: newRetrier(optFns),
+	}
+}
+
+type retryNotificationHandlerBehavior struct {
+	retrier *retrier
+}
+
+func (r *retryNotificationHandlerBehavior) Handle(ctx context.Context, notification Notification, handler interface{},
+	next NotificationNextFunc) error {
+	return r.retrier.do(ctx, func() error {
+		return next(ctx)
+	})
+}
+
+// retrier runs an attempt until it succeeds, fails with an error which is not retryable or has no attempt left
+type retrier struct {
+	options RetryOptions
+}
+
+func newRetrier(optFns []func(*RetryOptions)) *retrier {
+	options := RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Classifier:     IsRetryable,
+	}
+	for _, optFn := range optFns {
+		optFn(&options)
+	}
+	return &retrier{
+		options: options,
+	}
+}
+
+func (r *retrier) do(ctx context.Context, attempt func() error) error {
+	for attempts := 1; ; attempts++ {
+		err := attempt()
+		if err == nil || attempts >= r.options.MaxAttempts || !r.retryable(err) {
+			return err
+		}
+
+		delay := r.jitter(r.backoff(attempts))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay after the failed attempt, it grows exponentially up to the maximum backoff
+func (r *retrier) backoff(attempts int) time.Duration {
+	backoff := float64(r.options.InitialBackoff) * math.Pow(r.options.Multiplier, float64(attempts-1))
+	if r.options.MaxBackoff > 0 && backoff > float64(r.options.MaxBackoff) {
+		return r.options.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// retryable reports whether the error can be retried, the stop of the propagation and the end of the context never are
+func (r *retrier) retryable(err error) bool {
+	if errors.Is(err, ErrStopPropagation) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return r.options.Classifier != nil && r.options.Classifier(err)
+}
+
+// jitter randomizes the delay down to the jitter fraction of it
+func (r *retrier) jitter(delay time.Duration) time.Duration {
+	if r.options.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	jitter := min(r.options.Jitter, 1)
+	return delay - time.Duration(rand.Float64()*jitter*float64(delay))
+}
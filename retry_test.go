@@ -0,0 +1,222 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TestTransientError struct {
+}
+
+func (e TestTransientError) Error() string {
+	return "transient"
+}
+
+func (e TestTransientError) Retryable() bool {
+	return true
+}
+
+type TestRetryableRequest struct {
+	Value string
+}
+
+func (t TestRetryableRequest) String() string {
+	return t.Value
+}
+
+func (t TestRetryableRequest) Retryable() bool {
+	return true
+}
+
+// TestFlakyRequestHandler fails with its error until its attempt number reaches its successful attempt
+type TestFlakyRequestHandler struct {
+	attempts   *int
+	successful int
+	err        error
+}
+
+func (t TestFlakyRequestHandler) Handle(ctx context.Context, request TestRetryableRequest) (string, error) {
+	*t.attempts++
+	if *t.attempts < t.successful {
+		return "", t.err
+	}
+	return request.Value, nil
+}
+
+type TestFlakyNotificationHandler struct {
+	attempts   int
+	successful int
+	err        error
+}
+
+func (t *TestFlakyNotificationHandler) Handle(ctx context.Context, notification TestNotification) error {
+	t.attempts++
+	if t.attempts < t.successful {
+		return t.err
+	}
+	return nil
+}
+
+// TestFlakyEventHandlers handles the domain events and the user created notifications, its domain event handler
+// fails with a retryable error until its successful attempt
+type TestFlakyEventHandlers struct {
+	successful int
+	events     int
+	created    int
+}
+
+func (t *TestFlakyEventHandlers) HandleDomainEvent(ctx context.Context, event TestDomainEvent) error {
+	t.events++
+	if t.events < t.successful {
+		return TestTransientError{}
+	}
+	return nil
+}
+
+func (t *TestFlakyEventHandlers) HandleUserCreated(ctx context.Context, notification TestUserCreated) error {
+	t.created++
+	return nil
+}
+
+func newTestRetryContainer(attempts *int, successful int, err error, optFns ...func(*mediator.RetryOptions)) mediator.SendContainer {
+	optFns = append([]func(*mediator.RetryOptions){mediator.WithRetryBackoff(time.Millisecond, 5*time.Millisecond)}, optFns...)
+	return mediator.NewSendContainer(
+		mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[TestRetryableRequest, string](
+			TestFlakyRequestHandler{attempts: attempts, successful: successful, err: err})),
+		mediator.WithContextPipelineBehavior(mediator.NewRetryPipelineBehavior(optFns...)),
+	)
+}
+
+func TestRetryPipelineBehavior(t *testing.T) {
+	t.Run("should retry the retryable errors until the handler succeeds", func(t *testing.T) {
+		attempts := 0
+		container := newTestRetryContainer(&attempts, 3, TestTransientError{})
+
+		response, err := mediator.Send[TestRetryableRequest, string](context.Background(), container, TestRetryableRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("should stop after the maximum number of attempts", func(t *testing.T) {
+		attempts := 0
+		container := newTestRetryContainer(&attempts, 10, TestTransientError{}, mediator.WithRetryMaxAttempts(4))
+
+		_, err := mediator.Send[TestRetryableRequest, string](context.Background(), container, TestRetryableRequest{Value: "test"})
+		assert.ErrorAs(t, err, &TestTransientError{})
+		var dispatchErr *mediator.DispatchError
+		assert.ErrorAs(t, err, &dispatchErr)
+		assert.Equal(t, mediator.StageHandler, dispatchErr.Stage)
+		assert.Equal(t, 4, attempts)
+	})
+
+	t.Run("should not retry the errors which are not retryable", func(t *testing.T) {
+		attempts := 0
+		container := newTestRetryContainer(&attempts, 3, errors.New("invalid"))
+
+		_, err := mediator.Send[TestRetryableRequest, string](context.Background(), container, TestRetryableRequest{Value: "test"})
+		assert.ErrorContains(t, err, "invalid")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("should retry the errors accepted by the classifier", func(t *testing.T) {
+		invalidErr := errors.New("invalid")
+		attempts := 0
+		container := newTestRetryContainer(&attempts, 2, invalidErr, mediator.WithRetryClassifier(func(err error) bool {
+			return errors.Is(err, invalidErr)
+		}))
+
+		_, err := mediator.Send[TestRetryableRequest, string](context.Background(), container, TestRetryableRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("should only retry the requests opting in", func(t *testing.T) {
+		calls := 0
+		handler := mediator.HandlerFunc[*TestRequest, string](func(ctx context.Context, request *TestRequest) (string, error) {
+			calls++
+			return "", TestTransientError{}
+		})
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](handler)),
+			mediator.WithContextPipelineBehavior(mediator.NewRetryPipelineBehavior(mediator.WithRetryBackoff(time.Millisecond, time.Millisecond))),
+		)
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+
+		calls = 0
+		container = mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](handler)),
+			mediator.WithContextPipelineBehavior(mediator.NewRetryPipelineBehavior(mediator.WithRetryBackoff(time.Millisecond, time.Millisecond),
+				mediator.WithRetryAllRequests())),
+		)
+		_, err = mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should not wait for a retry beyond the deadline of the context", func(t *testing.T) {
+		attempts := 0
+		container := newTestRetryContainer(&attempts, 3, TestTransientError{}, mediator.WithRetryBackoff(time.Hour, time.Hour))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		start := time.Now()
+		_, err := mediator.Send[TestRetryableRequest, string](ctx, container, TestRetryableRequest{Value: "test"})
+		assert.ErrorAs(t, err, &TestTransientError{})
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestRetryNotificationHandlerBehavior(t *testing.T) {
+	t.Run("should retry each failed handler", func(t *testing.T) {
+		flaky := &TestFlakyNotificationHandler{successful: 2, err: TestTransientError{}}
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](flaky)),
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			mediator.WithNotificationHandlerBehavior(mediator.NewRetryNotificationHandlerBehavior(mediator.WithRetryBackoff(time.Millisecond, time.Millisecond))),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, flaky.attempts)
+		assert.True(t, handler.Executed)
+	})
+
+	t.Run("should not retry the stop of the propagation", func(t *testing.T) {
+		flaky := &TestFlakyNotificationHandler{successful: 2, err: mediator.ErrStopPropagation}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](flaky)),
+			mediator.WithNotificationHandlerBehavior(mediator.NewRetryNotificationHandlerBehavior(
+				mediator.WithRetryBackoff(time.Millisecond, time.Millisecond),
+				mediator.WithRetryClassifier(func(err error) bool {
+					return true
+				}))),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, flaky.attempts)
+	})
+	t.Run("should retry the handler resolved for the notification type and for an interface it implements", func(t *testing.T) {
+		handlers := &TestFlakyEventHandlers{successful: 3}
+		definitions, err := mediator.RegisterHandlersOf(handlers)
+		assert.NoError(t, err)
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(definitions.Notifications...),
+			mediator.WithNotificationHandlerBehavior(mediator.NewRetryNotificationHandlerBehavior(mediator.WithRetryBackoff(time.Millisecond, time.Millisecond))),
+		)
+
+		err = mediator.PublishWithoutContext(container, TestUserCreated{ID: "1"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, handlers.events)
+		assert.Equal(t, 1, handlers.created)
+	})
+}
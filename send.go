@@ -2,7 +2,8 @@ package mediator
 
 import (
 	"context"
-	"fmt"
+	"iter"
+	"reflect"
 )
 
 // SendWithoutContext sends a request to a single handler without a context
@@ -12,90 +13,85 @@ func SendWithoutContext[TRequest Request[TResponse], TResponse interface{}](cont
 }
 
 // Send sends a request to a single handler
+// The call options apply to this call only
 func Send[TRequest Request[TResponse], TResponse interface{}](ctx context.Context,
 	container SendContainer,
-	request TRequest) (TResponse, error) {
+	request TRequest,
+	optFns ...func(*CallOptions)) (TResponse, error) {
+	call, ctx := newCall(ctx, optFns)
 
-	handler, exists := container.resolve(request)
-	if !exists {
-		return *new(TResponse), fmt.Errorf("no handlers for request %T", request)
-	}
-	handlerValue, ok := handler.(RequestHandler[TRequest, TResponse])
-	if !ok {
-		return *new(TResponse), fmt.Errorf("handler for request %T is not a Handle", request)
-	}
-	var requestHandlerBehavior RequestHandlerFunc = func() (interface{}, error) {
-		return handlerValue.Handle(ctx, request)
+	handler, resolvedRequest, err := container.resolveRequest(request)
+	if err != nil {
+		return *new(TResponse), call.endRequest(nil, err)
 	}
-	response, err := container.executeWithPipeline(ctx, request, requestHandlerBehavior)
+	dispatch := newDispatchContext(ctx)
+	response, err := container.executeWithPipeline(dispatch, handler, resolvedRequest, call.callOptions())
 	if err != nil {
+		err = dispatch.newDispatchError(resolvedRequest, handler.handler, err)
 		if r, ok := response.(TResponse); ok {
-			return r, err
+			return r, call.endRequest(handler, err)
 		}
-		return *new(TResponse), err
+		return *new(TResponse), call.endRequest(handler, err)
 	}
-	return response.(TResponse), nil
+	typed, err := typedResponse[TResponse](request, response)
+	return typed, call.endRequest(handler, err)
 }
 
-func executeWithPipeline[TRequest Request[TResponse], TResponse interface{}](ctx context.Context,
-	pipelineBehaviors []PipelineBehavior,
-	handler RequestHandler[TRequest, TResponse],
-	request TRequest) (TResponse, error) {
-	if len(pipelineBehaviors) > 0 {
-		var reversPipes = reversOrder(pipelineBehaviors)
-
-		var lastHandler RequestHandlerFunc = func() (interface{}, error) {
-			return handler.Handle(ctx, request)
-		}
-		v := buildPipeline(reversPipes, lastHandler,
-			func(next RequestHandlerFunc, pipe PipelineBehavior) RequestHandlerFunc {
-				pipeValue := pipe
-				nexValue := next
-
-				var handlerFunc RequestHandlerFunc = func() (interface{}, error) {
-					return pipeValue.Handle(ctx, request, nexValue)
-				}
-
-				return handlerFunc
-			})
-
-		response, err := v()
-
-		if err != nil {
-			if r, ok := response.(TResponse); ok {
-				return r, err
-			}
-			return *new(TResponse), err
-		}
-
-		return response.(TResponse), nil
-	} else {
-		return handler.Handle(ctx, request)
+// typedResponse converts the response of the pipeline to the response type of the request
+func typedResponse[TResponse interface{}](request BaseRequest, response interface{}) (TResponse, error) {
+	if typed, ok := response.(TResponse); ok {
+		return typed, nil
+	}
+	responseType := reflect.TypeOf((*TResponse)(nil)).Elem()
+	if response == nil && isNillable(responseType) {
+		return *new(TResponse), nil
+	}
+	return *new(TResponse), &DispatchError{
+		RequestType: reflect.TypeOf(request),
+		Stage:       StagePipeline,
+		Err: &ResponseTypeMismatchError{
+			RequestType:  reflect.TypeOf(request),
+			ExpectedType: responseType,
+			ActualType:   reflect.TypeOf(response),
+		},
 	}
 }
 
 type Sender interface {
-	Send(ctx context.Context, request BaseRequest) (interface{}, error)
+	Send(ctx context.Context, request BaseRequest, optFns ...func(*CallOptions)) (interface{}, error)
+	Stream(ctx context.Context, request BaseRequest) (iter.Seq2[interface{}, error], error)
 }
 
-// RequestHandlerFunc is a function that handles a request
+// RequestHandlerFunc is a function that executes the next step of a PipelineBehavior
+// It runs the rest of the pipeline with the context and request given to the behavior.
+// A function handling a request is a HandlerFunc
 type RequestHandlerFunc func() (interface{}, error)
 
-func buildPipeline(a []PipelineBehavior, seed RequestHandlerFunc,
-	f func(RequestHandlerFunc, PipelineBehavior) RequestHandlerFunc) RequestHandlerFunc {
-	result := seed
-	for _, pipelineBehavior := range a {
-		result = f(result, pipelineBehavior)
-	}
-	return result
-}
-
-func reversOrder(values []PipelineBehavior) []PipelineBehavior {
-	var reverseValues []PipelineBehavior
-
-	for i := len(values) - 1; i >= 0; i-- {
-		reverseValues = append(reverseValues, values[i])
+// compilePipeline chains the behaviors in front of the handler, the first behavior being the outermost
+// When the response type is known, a behavior returning a response of another type fails with a ResponseTypeMismatchError
+func compilePipeline(behaviors []ContextPipelineBehavior, handler NextFunc, responseType reflect.Type) NextFunc {
+	next := handler
+	for i := len(behaviors) - 1; i >= 0; i-- {
+		behavior := behaviors[i]
+		inner := next
+		if responseType == nil {
+			next = func(ctx context.Context, request BaseRequest) (interface{}, error) {
+				return behavior.Handle(ctx, request, inner)
+			}
+			continue
+		}
+		next = func(ctx context.Context, request BaseRequest) (interface{}, error) {
+			response, err := behavior.Handle(ctx, request, inner)
+			if err == nil && !isResponseOf(response, responseType) {
+				return nil, &ResponseTypeMismatchError{
+					RequestType:  reflect.TypeOf(request),
+					ExpectedType: responseType,
+					ActualType:   reflect.TypeOf(response),
+					Behavior:     behaviorType(behavior),
+				}
+			}
+			return response, err
+		}
 	}
-
-	return reverseValues
+	return next
 }
@@ -2,61 +2,236 @@ package mediator
 
 import (
 	"context"
+	"iter"
 	"reflect"
+	"sync"
+	"sync/atomic"
 )
 
 // SendContainer is the mediator container for request and notification handlers
 // It is responsible for resolving handlers and pipeline behaviors
+// Handlers and behaviors can be registered on a live container, safely with the requests being sent
 type SendContainer interface {
-	resolve(request interface{}) (interface{}, bool)
+	// Validate reports the misconfigurations of the container
+	Validate() error
+	// RegisterRequestHandler registers a request handler,
+	// it atomically replaces the handler registered for the same request type
+	RegisterRequestHandler(definition RequestHandlerDefinition) Unregister
+	// RegisterStreamRequestHandler registers a stream request handler,
+	// it atomically replaces the handler registered for the same request type
+	RegisterStreamRequestHandler(definition StreamRequestHandlerDefinition) Unregister
+	// RegisterPipelineBehavior registers a pipeline behavior after the other behaviors
+	RegisterPipelineBehavior(pipelineBehavior ContextPipelineBehavior) Unregister
+	// RegisterStreamPipelineBehavior registers a stream pipeline behavior after the other stream behaviors
+	RegisterStreamPipelineBehavior(streamPipelineBehavior StreamPipelineBehavior) Unregister
+	resolveRequest(request BaseRequest) (*requestHandlerEntry, BaseRequest, error)
 	executeWithPipeline(ctx context.Context,
+		handler *requestHandlerEntry,
 		request BaseRequest,
-		requestHandlerBehavior RequestHandlerFunc) (interface{}, error)
+		options *CallOptions) (interface{}, error)
+	resolveStream(request BaseRequest) (*streamHandlerEntry, BaseRequest, error)
+	executeStreamWithPipeline(ctx context.Context,
+		handler *streamHandlerEntry,
+		request BaseRequest) iter.Seq2[interface{}, error]
 }
 
 type sendContainer struct {
-	requestHandlers map[reflect.Type]interface{}
-	pipelines       []PipelineBehavior
+	// state is the snapshot used by the dispatch, it is replaced on each registration
+	state atomic.Pointer[sendState]
+	// mu serializes the registrations
+	mu                  sync.Mutex
+	lastID              uint64
+	interfaceResolution bool
+	processors          []ContextPipelineBehavior
+	fallback            FallbackRequestHandler
 }
 
-func (c sendContainer) resolve(request interface{}) (interface{}, bool) {
-	handler, ok := c.requestHandlers[reflect.TypeOf(request)]
-	return handler, ok
+// sendRegistrations are the handlers and behaviors registered on a send container
+type sendRegistrations struct {
+	definitions       registrations[RequestHandlerDefinition]
+	streamDefinitions registrations[StreamRequestHandlerDefinition]
+	behaviors         registrations[ContextPipelineBehavior]
+	streamBehaviors   registrations[StreamPipelineBehavior]
 }
 
-func (c sendContainer) executeWithPipeline(ctx context.Context,
+// sendState is an immutable snapshot of a send container
+// The handler entries carry their compiled pipeline, so a request runs entirely with the snapshot it was resolved with
+type sendState struct {
+	registrations   sendRegistrations
+	requestHandlers *requestResolver[*requestHandlerEntry]
+	streamHandlers  *requestResolver[*streamHandlerEntry]
+	// pipelines are the pipeline behaviors followed by the request processors
+	pipelines []ContextPipelineBehavior
+}
+
+func (c *sendContainer) resolveRequest(request BaseRequest) (*requestHandlerEntry, BaseRequest, error) {
+	return c.state.Load().requestHandlers.resolve(request)
+}
+
+func (c *sendContainer) resolveStream(request BaseRequest) (*streamHandlerEntry, BaseRequest, error) {
+	return c.state.Load().streamHandlers.resolve(request)
+}
+
+func (c *sendContainer) executeStreamWithPipeline(ctx context.Context,
+	handler *streamHandlerEntry,
+	request BaseRequest) iter.Seq2[interface{}, error] {
+	return handler.pipeline(ctx, request)
+}
+
+func (c *sendContainer) executeWithPipeline(ctx context.Context,
+	handler *requestHandlerEntry,
 	request BaseRequest,
-	requestHandlerBehavior RequestHandlerFunc) (interface{}, error) {
-	if len(c.pipelines) > 0 {
-		var reversPipes = reversOrder(c.pipelines)
+	options *CallOptions) (interface{}, error) {
+	pipeline := handler.pipeline
+	if options.changesPipeline() {
+		pipeline = compilePipeline(options.pipelineBehaviors(handler.behaviors), handler.invoke, handler.responseType)
+	}
+	return pipeline(ctx, request)
+}
+
+func (c *sendContainer) RegisterRequestHandler(definition RequestHandlerDefinition) Unregister {
+	return c.register(func(registrations *sendRegistrations, id uint64) {
+		replaced, _ := registrations.definitions.filter(func(registration registration[RequestHandlerDefinition]) bool {
+			return registration.value.RequestType() != definition.RequestType()
+		})
+		registrations.definitions = replaced.with(id, definition)
+	}, func(registrations *sendRegistrations, id uint64) (removed bool) {
+		registrations.definitions, removed = registrations.definitions.without(id)
+		return removed
+	})
+}
 
-		v := buildPipeline(reversPipes, requestHandlerBehavior,
-			func(next RequestHandlerFunc, pipe PipelineBehavior) RequestHandlerFunc {
-				pipeValue := pipe
-				nexValue := next
+func (c *sendContainer) RegisterStreamRequestHandler(definition StreamRequestHandlerDefinition) Unregister {
+	return c.register(func(registrations *sendRegistrations, id uint64) {
+		replaced, _ := registrations.streamDefinitions.filter(func(registration registration[StreamRequestHandlerDefinition]) bool {
+			return registration.value.RequestType() != definition.RequestType()
+		})
+		registrations.streamDefinitions = replaced.with(id, definition)
+	}, func(registrations *sendRegistrations, id uint64) (removed bool) {
+		registrations.streamDefinitions, removed = registrations.streamDefinitions.without(id)
+		return removed
+	})
+}
 
-				var handlerFunc RequestHandlerFunc = func() (interface{}, error) {
-					return pipeValue.Handle(ctx, request, nexValue)
-				}
+func (c *sendContainer) RegisterPipelineBehavior(pipelineBehavior ContextPipelineBehavior) Unregister {
+	return c.register(func(registrations *sendRegistrations, id uint64) {
+		registrations.behaviors = registrations.behaviors.with(id, pipelineBehavior)
+	}, func(registrations *sendRegistrations, id uint64) (removed bool) {
+		registrations.behaviors, removed = registrations.behaviors.without(id)
+		return removed
+	})
+}
 
-				return handlerFunc
-			})
+func (c *sendContainer) RegisterStreamPipelineBehavior(streamPipelineBehavior StreamPipelineBehavior) Unregister {
+	return c.register(func(registrations *sendRegistrations, id uint64) {
+		registrations.streamBehaviors = registrations.streamBehaviors.with(id, streamPipelineBehavior)
+	}, func(registrations *sendRegistrations, id uint64) (removed bool) {
+		registrations.streamBehaviors, removed = registrations.streamBehaviors.without(id)
+		return removed
+	})
+}
 
-		response, err := v()
+// register applies a registration and publishes the new snapshot,
+// the returned Unregister removes it the same way
+func (c *sendContainer) register(add func(*sendRegistrations, uint64),
+	remove func(*sendRegistrations, uint64) bool) Unregister {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID++
+	id := c.lastID
+	registrations := c.state.Load().registrations
+	add(&registrations, id)
+	c.state.Store(c.build(registrations))
 
-		if err != nil {
-			return response, err
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		registrations := c.state.Load().registrations
+		if remove(&registrations, id) {
+			c.state.Store(c.build(registrations))
 		}
+	}
+}
 
-		return response, nil
-	} else {
-		return requestHandlerBehavior()
+// build creates the snapshot of the registrations, compiling the pipeline of each handler
+func (c *sendContainer) build(registrations sendRegistrations) *sendState {
+	pipelines := registrations.behaviors.values()
+	pipelines = append(pipelines, c.processors...)
+	requestEntries := make(map[reflect.Type]*requestHandlerEntry, len(registrations.definitions))
+	for _, definition := range registrations.definitions.values() {
+		entry := newRequestHandlerEntry(definition)
+		entry.pipeline = compilePipeline(pipelines, entry.invoke, entry.responseType)
+		entry.behaviors = pipelines
+		requestEntries[entry.requestType] = entry
+	}
+	requestHandlers := newRequestResolver("request", requestEntries, c.interfaceResolution)
+	if c.fallback != nil {
+		fallback := newRequestHandlerEntry(fallbackRequestHandlerDefinition{handler: c.fallback})
+		fallback.pipeline = compilePipeline(pipelines, fallback.invoke, nil)
+		fallback.behaviors = pipelines
+		requestHandlers.withFallback(fallback)
+	}
+	streamPipelines := registrations.streamBehaviors.values()
+	streamHandlers := make(map[reflect.Type]*streamHandlerEntry, len(registrations.streamDefinitions))
+	for _, definition := range registrations.streamDefinitions.values() {
+		entry := newStreamHandlerEntry(definition)
+		entry.pipeline = compileStreamPipeline(streamPipelines, entry.invoke)
+		streamHandlers[definition.RequestType()] = entry
+	}
+	return &sendState{
+		registrations:   registrations,
+		requestHandlers: requestHandlers,
+		streamHandlers:  newRequestResolver("stream request", streamHandlers, c.interfaceResolution),
+		pipelines:       pipelines,
 	}
 }
 
 type SendContainerOptions struct {
-	RequestDefinitionHandlers []RequestHandlerDefinition
-	PipelineBehaviors         []PipelineBehavior
+	RequestDefinitionHandlers       []RequestHandlerDefinition
+	PipelineBehaviors               []PipelineBehavior
+	ContextPipelineBehaviors        []ContextPipelineBehavior
+	StreamRequestDefinitionHandlers []StreamRequestHandlerDefinition
+	StreamPipelineBehaviors         []StreamPipelineBehavior
+	RequestPreProcessors            []ContextPipelineBehavior
+	RequestPostProcessors           []ContextPipelineBehavior
+	RequestExceptionHandlers        []ContextPipelineBehavior
+	InterfaceRequestResolution      bool
+	StrictValidation                bool
+	FallbackRequestHandler          FallbackRequestHandler
+	// contextPipelineOrder tells, for each pipeline behavior added with an option, whether it is a context pipeline behavior,
+	// so the behaviors of PipelineBehaviors and ContextPipelineBehaviors run in registration order
+	contextPipelineOrder []bool
+}
+
+func (o *SendContainerOptions) addPipelineBehavior(pipelineBehavior PipelineBehavior) {
+	o.PipelineBehaviors = append(o.PipelineBehaviors, pipelineBehavior)
+	o.contextPipelineOrder = append(o.contextPipelineOrder, false)
+}
+
+func (o *SendContainerOptions) addContextPipelineBehavior(pipelineBehavior ContextPipelineBehavior) {
+	o.ContextPipelineBehaviors = append(o.ContextPipelineBehaviors, pipelineBehavior)
+	o.contextPipelineOrder = append(o.contextPipelineOrder, true)
+}
+
+// pipelineBehaviors merges the pipeline behaviors and the context pipeline behaviors in registration order
+// The behaviors set on the fields without an option run last, the pipeline behaviors first
+func (o *SendContainerOptions) pipelineBehaviors() []ContextPipelineBehavior {
+	pipelineBehaviors, contextBehaviors := o.PipelineBehaviors, o.ContextPipelineBehaviors
+	behaviors := make([]ContextPipelineBehavior, 0, len(pipelineBehaviors)+len(contextBehaviors))
+	for _, isContext := range o.contextPipelineOrder {
+		switch {
+		case isContext && len(contextBehaviors) > 0:
+			behaviors = append(behaviors, contextBehaviors[0])
+			contextBehaviors = contextBehaviors[1:]
+		case !isContext && len(pipelineBehaviors) > 0:
+			behaviors = append(behaviors, AdaptPipelineBehavior(pipelineBehaviors[0]))
+			pipelineBehaviors = pipelineBehaviors[1:]
+		}
+	}
+	for _, pipelineBehavior := range pipelineBehaviors {
+		behaviors = append(behaviors, AdaptPipelineBehavior(pipelineBehavior))
+	}
+	return append(behaviors, contextBehaviors...)
 }
 
 // WithRequestDefinitionHandler adds a request handler to the container
@@ -76,14 +251,68 @@ func WithRequestDefinitionHandlers(requestHandlers ...RequestHandlerDefinition)
 // WithPipelineBehavior adds a pipeline behavior to the container
 func WithPipelineBehavior(pipelineBehavior PipelineBehavior) func(*SendContainerOptions) {
 	return func(options *SendContainerOptions) {
-		options.PipelineBehaviors = append(options.PipelineBehaviors, pipelineBehavior)
+		options.addPipelineBehavior(pipelineBehavior)
 	}
 }
 
 // WithPipelineBehaviors adds pipeline behaviors to the container
 func WithPipelineBehaviors(pipelineBehaviors []PipelineBehavior) func(*SendContainerOptions) {
 	return func(options *SendContainerOptions) {
-		options.PipelineBehaviors = append(options.PipelineBehaviors, pipelineBehaviors...)
+		for _, pipelineBehavior := range pipelineBehaviors {
+			options.addPipelineBehavior(pipelineBehavior)
+		}
+	}
+}
+
+// WithContextPipelineBehavior adds a context pipeline behavior to the container
+func WithContextPipelineBehavior(pipelineBehavior ContextPipelineBehavior) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.addContextPipelineBehavior(pipelineBehavior)
+	}
+}
+
+// WithContextPipelineBehaviors adds context pipeline behaviors to the container
+func WithContextPipelineBehaviors(pipelineBehaviors []ContextPipelineBehavior) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		for _, pipelineBehavior := range pipelineBehaviors {
+			options.addContextPipelineBehavior(pipelineBehavior)
+		}
+	}
+}
+
+// WithInterfaceRequestResolution resolves the requests and stream requests without handler for their type
+// to the handler registered for an interface they implement
+func WithInterfaceRequestResolution() func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.InterfaceRequestResolution = true
+	}
+}
+
+// WithStreamRequestDefinitionHandler adds a stream request handler to the container
+func WithStreamRequestDefinitionHandler(streamRequestHandler StreamRequestHandlerDefinition) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.StreamRequestDefinitionHandlers = append(options.StreamRequestDefinitionHandlers, streamRequestHandler)
+	}
+}
+
+// WithStreamRequestDefinitionHandlers adds stream request handlers to the container
+func WithStreamRequestDefinitionHandlers(streamRequestHandlers ...StreamRequestHandlerDefinition) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.StreamRequestDefinitionHandlers = append(options.StreamRequestDefinitionHandlers, streamRequestHandlers...)
+	}
+}
+
+// WithStreamPipelineBehavior adds a stream pipeline behavior to the container
+func WithStreamPipelineBehavior(streamPipelineBehavior StreamPipelineBehavior) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.StreamPipelineBehaviors = append(options.StreamPipelineBehaviors, streamPipelineBehavior)
+	}
+}
+
+// WithStreamPipelineBehaviors adds stream pipeline behaviors to the container
+func WithStreamPipelineBehaviors(streamPipelineBehaviors []StreamPipelineBehavior) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.StreamPipelineBehaviors = append(options.StreamPipelineBehaviors, streamPipelineBehaviors...)
 	}
 }
 
@@ -92,13 +321,38 @@ func NewSendContainer(optFns ...func(*SendContainerOptions)) SendContainer {
 	for _, optFn := range optFns {
 		optFn(options)
 	}
-	requestDefinitionHandlers := options.RequestDefinitionHandlers
-	requestHandlers := make(map[reflect.Type]interface{}, len(requestDefinitionHandlers))
-	for _, requestHandler := range requestDefinitionHandlers {
-		requestHandlers[requestHandler.RequestType()] = requestHandler.Handler()
+	return newSendContainer(options)
+}
+
+func newSendContainer(options *SendContainerOptions) *sendContainer {
+	container := &sendContainer{
+		interfaceResolution: options.InterfaceRequestResolution,
+		processors:          requestProcessorBehaviors(options),
+		fallback:            options.FallbackRequestHandler,
 	}
-	return sendContainer{
-		requestHandlers: requestHandlers,
-		pipelines:       options.PipelineBehaviors,
+	var registrations sendRegistrations
+	for _, definition := range options.RequestDefinitionHandlers {
+		container.lastID++
+		registrations.definitions = registrations.definitions.with(container.lastID, definition)
+	}
+	for _, definition := range options.StreamRequestDefinitionHandlers {
+		container.lastID++
+		registrations.streamDefinitions = registrations.streamDefinitions.with(container.lastID, definition)
+	}
+	for _, behavior := range options.pipelineBehaviors() {
+		container.lastID++
+		registrations.behaviors = registrations.behaviors.with(container.lastID, behavior)
+	}
+	for _, behavior := range options.StreamPipelineBehaviors {
+		container.lastID++
+		registrations.streamBehaviors = registrations.streamBehaviors.with(container.lastID, behavior)
+	}
+	// The pipelines are compiled once per handler, the dispatch only runs them
+	container.state.Store(container.build(registrations))
+	if options.StrictValidation {
+		if err := container.Validate(); err != nil {
+			panic(err)
+		}
 	}
+	return container
 }
@@ -0,0 +1,196 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrNoHandler is the error of a request sent without a handler registered for its type
+var ErrNoHandler = errors.New("mediator: no handler")
+
+// ErrAmbiguousHandler is the error of a request matching the handlers of several types
+var ErrAmbiguousHandler = errors.New("mediator: ambiguous handlers")
+
+// ErrInvalidHandler is the error of a handler which cannot handle the messages it is registered for
+var ErrInvalidHandler = errors.New("mediator: invalid handler")
+
+// DispatchStage is the step of the dispatch of a request
+type DispatchStage int
+
+const (
+	// StageResolve is the resolution of the handler of the request
+	StageResolve DispatchStage = iota
+	// StagePipeline is the execution of the pipeline behaviors and request processors
+	StagePipeline
+	// StageHandler is the execution of the request handler
+	StageHandler
+)
+
+func (s DispatchStage) String() string {
+	switch s {
+	case StageResolve:
+		return "resolve"
+	case StagePipeline:
+		return "pipeline"
+	case StageHandler:
+		return "handler"
+	default:
+		return fmt.Sprintf("DispatchStage(%d)", int(s))
+	}
+}
+
+// DispatchError is the error returned when a request fails
+// It wraps the error of the failing stage, so errors.Is and errors.As can inspect it
+type DispatchError struct {
+	// RequestType is the type of the request
+	RequestType reflect.Type
+	// HandlerType is the type of the handler of the request, nil when it is not resolved
+	HandlerType reflect.Type
+	// Stage is the step of the dispatch which failed
+	Stage DispatchStage
+	// Err is the error of the stage
+	Err error
+}
+
+func (e *DispatchError) Error() string {
+	switch e.Stage {
+	case StageResolve:
+		return e.Err.Error()
+	case StageHandler:
+		return fmt.Sprintf("handler %s of request %s failed: %v", typeName(e.HandlerType), typeName(e.RequestType), e.Err)
+	default:
+		return fmt.Sprintf("%s of request %s failed: %v", e.Stage, typeName(e.RequestType), e.Err)
+	}
+}
+
+func (e *DispatchError) Unwrap() error {
+	return e.Err
+}
+
+// newResolveError returns the DispatchError of a request which cannot be resolved
+func newResolveError(request interface{}, err error) *DispatchError {
+	return &DispatchError{
+		RequestType: reflect.TypeOf(request),
+		Stage:       StageResolve,
+		Err:         err,
+	}
+}
+
+// dispatchContext is the context of the dispatch of a request, it records the last error returned by the request handler
+// The behaviors see the error of the handler as is, the record attributes the error to the handler stage afterward
+type dispatchContext struct {
+	context.Context
+	mu         sync.Mutex
+	handlerErr error
+}
+
+type dispatchContextKey struct{}
+
+// newDispatchContext returns the context of a dispatch recording the errors of the request handler
+func newDispatchContext(ctx context.Context) *dispatchContext {
+	return &dispatchContext{Context: ctx}
+}
+
+func (c *dispatchContext) Value(key interface{}) interface{} {
+	if key == (dispatchContextKey{}) {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// recordHandlerError records the error of the request handler in the dispatch of the context
+func recordHandlerError(ctx context.Context, err error) {
+	if dispatch, ok := ctx.Value(dispatchContextKey{}).(*dispatchContext); ok {
+		dispatch.mu.Lock()
+		dispatch.handlerErr = err
+		dispatch.mu.Unlock()
+	}
+}
+
+// newDispatchError returns the error of the pipeline of a request as a DispatchError
+// The error is attributed to the handler when it is, or wraps, the last error returned by the handler
+func (c *dispatchContext) newDispatchError(request BaseRequest, handler interface{}, err error) *DispatchError {
+	c.mu.Lock()
+	handlerErr := c.handlerErr
+	c.mu.Unlock()
+	stage := StagePipeline
+	if handlerErr != nil && errors.Is(err, handlerErr) {
+		stage = StageHandler
+	}
+	return &DispatchError{
+		RequestType: reflect.TypeOf(request),
+		HandlerType: reflect.TypeOf(handler),
+		Stage:       stage,
+		Err:         err,
+	}
+}
+
+// ResponseTypeMismatchError is the error returned when the response of a request is not of the response type
+// of the request, e.g. when a pipeline behavior short-circuits the handler with a value of another type
+type ResponseTypeMismatchError struct {
+	// RequestType is the type of the request
+	RequestType reflect.Type
+	// ExpectedType is the response type of the request
+	ExpectedType reflect.Type
+	// ActualType is the type of the response, nil when the response is nil
+	ActualType reflect.Type
+	// Behavior is the type of the pipeline behavior which returned the response,
+	// nil when it is not known
+	Behavior reflect.Type
+}
+
+func (e *ResponseTypeMismatchError) Error() string {
+	message := fmt.Sprintf("response %s of request %s is not a %s",
+		typeName(e.ActualType), typeName(e.RequestType), typeName(e.ExpectedType))
+	if e.Behavior != nil {
+		message += fmt.Sprintf(", returned by pipeline behavior %s", e.Behavior)
+	}
+	return message
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
+
+// isResponseOf reports whether the response can be returned for the response type
+// A nil response is valid for the types having nil as zero value
+func isResponseOf(response interface{}, responseType reflect.Type) bool {
+	if response == nil {
+		return isNillable(responseType)
+	}
+	actualType := reflect.TypeOf(response)
+	return actualType == responseType || actualType.AssignableTo(responseType)
+}
+
+func isNillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// wrappedBehavior is implemented by the behaviors adapting a behavior or a processor registered by the user
+type wrappedBehavior interface {
+	unwrap() interface{}
+}
+
+// userBehavior returns the behavior or processor registered by the user for a behavior of the pipeline
+func userBehavior(behavior ContextPipelineBehavior) interface{} {
+	if wrapped, ok := behavior.(wrappedBehavior); ok {
+		return wrapped.unwrap()
+	}
+	return behavior
+}
+
+// behaviorType returns the type of the behavior registered by the user for a behavior of the pipeline
+func behaviorType(behavior ContextPipelineBehavior) reflect.Type {
+	return reflect.TypeOf(userBehavior(behavior))
+}
@@ -2,8 +2,12 @@ package mediator_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/Oleexo/mediator-go"
 	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -30,6 +34,102 @@ func (t TestPipelineBehavior) Handle(ctx context.Context, request mediator.BaseR
 	return next()
 }
 
+type testContextKey struct{}
+
+type TestContextRequestHandler struct {
+}
+
+func (t TestContextRequestHandler) Handle(ctx context.Context, request *TestRequest) (string, error) {
+	value, _ := ctx.Value(testContextKey{}).(string)
+	return value + ":" + request.Value, nil
+}
+
+type TestContextPipelineBehavior struct {
+}
+
+func (t TestContextPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+	ctx = context.WithValue(ctx, testContextKey{}, "tenant")
+	return next(ctx, &TestRequest{Value: "substituted"})
+}
+
+type TestNamedRequest interface {
+	mediator.BaseRequest
+	Name() string
+}
+
+type TestTenantRequest interface {
+	mediator.BaseRequest
+	Tenant() string
+}
+
+type TestGreetRequest struct {
+	Value string
+}
+
+func (t TestGreetRequest) String() string {
+	return "TestGreetRequest"
+}
+
+func (t TestGreetRequest) Name() string {
+	return t.Value
+}
+
+func (t TestGreetRequest) Tenant() string {
+	return "tenant"
+}
+
+type TestNamedRequestHandler struct {
+}
+
+func (t TestNamedRequestHandler) Handle(ctx context.Context, request TestNamedRequest) (string, error) {
+	return "hello " + request.Name(), nil
+}
+
+type TestTenantRequestHandler struct {
+}
+
+func (t TestTenantRequestHandler) Handle(ctx context.Context, request TestTenantRequest) (string, error) {
+	return request.Tenant(), nil
+}
+
+type testCustomRequestHandlerDefinition struct {
+	handler interface{}
+}
+
+func (d testCustomRequestHandlerDefinition) RequestType() reflect.Type {
+	return reflect.TypeOf(&TestRequest{})
+}
+
+func (d testCustomRequestHandlerDefinition) Handler() interface{} {
+	return d.handler
+}
+
+type TestShortCircuitPipelineBehavior struct {
+	Response interface{}
+}
+
+func (t TestShortCircuitPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+	return t.Response, nil
+}
+
+type TestZeroResponseHandler[TResponse interface{}] struct {
+}
+
+func (t TestZeroResponseHandler[TResponse]) Handle(ctx context.Context, request *TestRequest) (TResponse, error) {
+	return *new(TResponse), nil
+}
+
+func testSendNilResponse[TResponse interface{}](t *testing.T) {
+	container := mediator.NewSendContainer(
+		mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, TResponse](TestZeroResponseHandler[TResponse]{})),
+		mediator.WithContextPipelineBehavior(TestShortCircuitPipelineBehavior{Response: nil}),
+	)
+
+	response, err := mediator.Send[*TestRequest, TResponse](context.Background(), container, &TestRequest{Value: "test"})
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+}
+
 func TestSend(t *testing.T) {
 	t.Run("should send a request to a single handler", func(t *testing.T) {
 		handler := TestRequestHandler{}
@@ -59,4 +159,301 @@ func TestSend(t *testing.T) {
 
 		assert.Equal(t, "pipeline", response)
 	})
+	t.Run("should forward the context and request of a context pipeline behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestContextRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "tenant:substituted", response)
+	})
+
+	t.Run("should forward the context to behaviors adapted after a context pipeline behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestContextRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+			mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "tenant:pipeline", response)
+	})
+
+	t.Run("should forward the context and request through the sender", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestContextRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+		)
+		sender := mediator.NewSender(container)
+
+		response, err := sender.Send(context.Background(), &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "tenant:substituted", response)
+	})
+
+	t.Run("should resolve a pointer handler for a request sent by value", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+		)
+
+		response, err := mediator.Send[TestRequest, string](context.Background(), container, TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response)
+
+		response2, err := mediator.NewSender(container).Send(context.Background(), TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pipeline", response2)
+	})
+
+	t.Run("should resolve a value handler for a request sent by pointer", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[TestOtherRequest, int](TestOtherRequestHandler{})),
+		)
+
+		response, err := mediator.Send[*TestOtherRequest, int](context.Background(), container, &TestOtherRequest{Value: 42})
+		assert.NoError(t, err)
+		assert.Equal(t, 42, response)
+
+		_, err = mediator.Send[*TestOtherRequest, int](context.Background(), container, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("should resolve an interface handler when enabled", func(t *testing.T) {
+		definition := mediator.NewRequestHandlerDefinition[TestNamedRequest, string](TestNamedRequestHandler{})
+
+		container := mediator.NewSendContainer(mediator.WithRequestDefinitionHandler(definition))
+		_, err := mediator.Send[TestGreetRequest, string](context.Background(), container, TestGreetRequest{Value: "john"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+
+		container = mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(definition),
+			mediator.WithInterfaceRequestResolution(),
+		)
+		response, err := mediator.Send[TestGreetRequest, string](context.Background(), container, TestGreetRequest{Value: "john"})
+		assert.NoError(t, err)
+		assert.Equal(t, "hello john", response)
+
+		response2, err := mediator.NewSender(container).Send(context.Background(), &TestGreetRequest{Value: "jane"})
+		assert.NoError(t, err)
+		assert.Equal(t, "hello jane", response2)
+	})
+
+	t.Run("should fail when several interface handlers match", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandlers(
+				mediator.NewRequestHandlerDefinition[TestNamedRequest, string](TestNamedRequestHandler{}),
+				mediator.NewRequestHandlerDefinition[TestTenantRequest, string](TestTenantRequestHandler{}),
+			),
+			mediator.WithInterfaceRequestResolution(),
+		)
+
+		_, err := mediator.Send[TestGreetRequest, string](context.Background(), container, TestGreetRequest{Value: "john"})
+		assert.ErrorIs(t, err, mediator.ErrAmbiguousHandler)
+		assert.EqualError(t, err, "mediator: ambiguous handlers for request mediator_test.TestGreetRequest: registered for mediator_test.TestNamedRequest, mediator_test.TestTenantRequest")
+	})
+
+	t.Run("should invoke the handler of a custom definition", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(testCustomRequestHandlerDefinition{handler: TestRequestHandler{}}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", response)
+
+		container = mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(testCustomRequestHandlerDefinition{handler: struct{}{}}),
+		)
+		_, err = mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+		assert.EqualError(t, err, "handler struct {} of request *mediator_test.TestRequest failed: mediator: invalid handler for request *mediator_test.TestRequest: struct {} has no Handle method")
+	})
+
+	t.Run("should fail with a ResponseTypeMismatchError when a behavior returns another type", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestShortCircuitPipelineBehavior{Response: 42}),
+		)
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.Equal(t, "", response)
+		var mismatchErr *mediator.ResponseTypeMismatchError
+		if assert.ErrorAs(t, err, &mismatchErr) {
+			assert.Equal(t, reflect.TypeOf(&TestRequest{}), mismatchErr.RequestType)
+			assert.Equal(t, reflect.TypeOf(""), mismatchErr.ExpectedType)
+			assert.Equal(t, reflect.TypeOf(0), mismatchErr.ActualType)
+			assert.Equal(t, reflect.TypeOf(TestShortCircuitPipelineBehavior{}), mismatchErr.Behavior)
+		}
+		assert.EqualError(t, err, "pipeline of request *mediator_test.TestRequest failed: response int of request *mediator_test.TestRequest is not a string, returned by pipeline behavior mediator_test.TestShortCircuitPipelineBehavior")
+
+		_, err = mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.ErrorAs(t, err, &mismatchErr)
+	})
+
+	t.Run("should fail with a ResponseTypeMismatchError when a behavior returns nil for a value type", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestShortCircuitPipelineBehavior{Response: nil}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.EqualError(t, err, "pipeline of request *mediator_test.TestRequest failed: response <nil> of request *mediator_test.TestRequest is not a string, returned by pipeline behavior mediator_test.TestShortCircuitPipelineBehavior")
+	})
+
+	t.Run("should return nil when a behavior returns nil for a nillable type", func(t *testing.T) {
+		t.Run("pointer", testSendNilResponse[*TestRequest])
+		t.Run("interface", testSendNilResponse[fmt.Stringer])
+		t.Run("slice", testSendNilResponse[[]string])
+		t.Run("map", testSendNilResponse[map[string]int])
+	})
+
+	t.Run("should fail with a ResponseTypeMismatchError when the response type of the handler is unknown", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(testCustomRequestHandlerDefinition{handler: TestRequestHandler{}}),
+			mediator.WithContextPipelineBehavior(TestShortCircuitPipelineBehavior{Response: 42}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		var mismatchErr *mediator.ResponseTypeMismatchError
+		assert.ErrorAs(t, err, &mismatchErr)
+		assert.EqualError(t, err, "pipeline of request *mediator_test.TestRequest failed: response int of request *mediator_test.TestRequest is not a string")
+	})
+
+	t.Run("should wrap the error of the handler in a DispatchError", func(t *testing.T) {
+		handlerErr := errors.New("not found")
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: handlerErr})),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, handlerErr)
+		var dispatchErr *mediator.DispatchError
+		if assert.ErrorAs(t, err, &dispatchErr) {
+			assert.Equal(t, mediator.StageHandler, dispatchErr.Stage)
+			assert.Equal(t, reflect.TypeOf(&TestRequest{}), dispatchErr.RequestType)
+			assert.Equal(t, reflect.TypeOf(TestFailingRequestHandler{}), dispatchErr.HandlerType)
+			assert.Equal(t, handlerErr, dispatchErr.Err)
+		}
+		assert.EqualError(t, err, "handler mediator_test.TestFailingRequestHandler of request *mediator_test.TestRequest failed: not found")
+
+		_, err = mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, handlerErr)
+		assert.ErrorAs(t, err, &dispatchErr)
+	})
+
+	t.Run("should give the error of the handler as is to the behaviors", func(t *testing.T) {
+		handlerErr := errors.New("not found")
+		var seen error
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestFailingRequestHandler{err: handlerErr})),
+			mediator.WithContextPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				response, err := next(ctx, request)
+				seen = err
+				return response, fmt.Errorf("wrapped: %w", err)
+			})),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.True(t, seen == handlerErr)
+		var dispatchErr *mediator.DispatchError
+		if assert.ErrorAs(t, err, &dispatchErr) {
+			assert.Equal(t, mediator.StageHandler, dispatchErr.Stage)
+			assert.EqualError(t, dispatchErr.Err, "wrapped: not found")
+		}
+	})
+
+	t.Run("should report the error of a behavior at the pipeline stage", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithTypedPipelineBehavior[*TestRequest, string](TestValidationPipelineBehavior{calls: &[]string{}}),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{})
+		var dispatchErr *mediator.DispatchError
+		if assert.ErrorAs(t, err, &dispatchErr) {
+			assert.Equal(t, mediator.StagePipeline, dispatchErr.Stage)
+			assert.Equal(t, reflect.TypeOf(TestRequestHandler{}), dispatchErr.HandlerType)
+		}
+	})
+
+	t.Run("should report a missing handler at the resolve stage", func(t *testing.T) {
+		container := mediator.NewSendContainer()
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+		var dispatchErr *mediator.DispatchError
+		if assert.ErrorAs(t, err, &dispatchErr) {
+			assert.Equal(t, mediator.StageResolve, dispatchErr.Stage)
+			assert.Nil(t, dispatchErr.HandlerType)
+		}
+		assert.EqualError(t, err, "mediator: no handler for request *mediator_test.TestRequest")
+
+		_, err = mediator.NewSender(container).Send(context.Background(), &TestRequest{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should send a request to a function handler through function behaviors", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](
+				mediator.HandlerFunc[*TestRequest, string](func(ctx context.Context, request *TestRequest) (string, error) {
+					return "hello " + request.Value, nil
+				}))),
+			mediator.WithPipelineBehavior(mediator.PipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.RequestHandlerFunc) (interface{}, error) {
+				response, err := next()
+				return response.(string) + "!", err
+			})),
+			mediator.WithContextPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				return next(ctx, &TestRequest{Value: strings.ToUpper(request.String())})
+			})),
+		)
+		assert.NoError(t, container.Validate())
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "john"})
+		assert.NoError(t, err)
+		assert.Equal(t, "hello JOHN!", response)
+	})
+	t.Run("should run the pipeline behaviors and the context pipeline behaviors in registration order", func(t *testing.T) {
+		var calls []string
+		record := func(name string) mediator.ContextPipelineBehavior {
+			return mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			})
+		}
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithContextPipelineBehavior(record("first")),
+			mediator.WithPipelineBehavior(mediator.PipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.RequestHandlerFunc) (interface{}, error) {
+				calls = append(calls, "second")
+				return next()
+			})),
+			mediator.WithContextPipelineBehavior(record("third")),
+		)
+
+		_, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second", "third"}, calls)
+	})
+
+	t.Run("should run the pipeline behaviors set on the options", func(t *testing.T) {
+		container := mediator.NewSendContainer(func(options *mediator.SendContainerOptions) {
+			options.RequestDefinitionHandlers = []mediator.RequestHandlerDefinition{
+				mediator.NewRequestHandlerDefinition[*TestRequest, string](TestContextRequestHandler{}),
+			}
+			options.PipelineBehaviors = []mediator.PipelineBehavior{TestPipelineBehavior{}}
+			options.ContextPipelineBehaviors = []mediator.ContextPipelineBehavior{TestContextPipelineBehavior{}}
+		})
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "tenant:substituted", response)
+	})
 }
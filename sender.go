@@ -3,6 +3,7 @@ package mediator
 import (
 	"context"
 	"fmt"
+	"iter"
 	"reflect"
 )
 
@@ -16,30 +17,72 @@ func NewSender(container SendContainer) Sender {
 	}
 }
 
-func (s sender) Send(ctx context.Context, request BaseRequest) (interface{}, error) {
-	handler, exists := s.container.resolve(request)
-	if !exists {
-		return nil, fmt.Errorf("no handlers for request %T", request)
+func (s sender) Send(ctx context.Context, request BaseRequest, optFns ...func(*CallOptions)) (interface{}, error) {
+	call, ctx := newCall(ctx, optFns)
+	handler, request, err := s.container.resolveRequest(request)
+	if err != nil {
+		return nil, call.endRequest(nil, err)
 	}
-	var requestHandlerBehavior RequestHandlerFunc = func() (interface{}, error) {
-		handlerMethod := reflect.ValueOf(handler).
-			MethodByName("Handle")
-		if !handlerMethod.IsValid() {
-			return nil, fmt.Errorf("handler for request %T is not a RequestHandler", request)
-		}
-		// Create a slice of reflect.Value with ctx and notification as arguments
-		args := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(request)}
 
-		// Call the method with ctx and notification as arguments and get the returned error
-		result := handlerMethod.Call(args)
+	dispatch := newDispatchContext(ctx)
+	response, err := s.container.executeWithPipeline(dispatch, handler, request, call.callOptions())
+	if err != nil {
+		return response, call.endRequest(handler, dispatch.newDispatchError(request, handler.handler, err))
+	}
+	return response, call.endRequest(handler, nil)
+}
 
-		valueResult := result[0].Interface()
-		errorResult := result[1].Interface()
-		if errorResult != nil {
-			return valueResult, errorResult.(error)
-		}
-		return valueResult, nil
+// invokeRequestHandler calls the Handle method of a request handler with reflection
+// It is only used for the definitions which are not created by NewRequestHandlerDefinition
+func invokeRequestHandler(ctx context.Context, handler interface{}, request BaseRequest) (interface{}, error) {
+	handlerMethod := reflect.ValueOf(handler).
+		MethodByName("Handle")
+	if !handlerMethod.IsValid() {
+		return nil, fmt.Errorf("%w for request %T: %T has no Handle method", ErrInvalidHandler, request, handler)
+	}
+	// Create a slice of reflect.Value with ctx and request as arguments
+	args := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(request)}
+
+	// Call the method with ctx and request as arguments and get the returned error
+	result := handlerMethod.Call(args)
+
+	valueResult := result[0].Interface()
+	errorResult := result[1].Interface()
+	if errorResult != nil {
+		return valueResult, errorResult.(error)
+	}
+	return valueResult, nil
+}
+
+func (s sender) Stream(ctx context.Context, request BaseRequest) (iter.Seq2[interface{}, error], error) {
+	handler, request, err := s.container.resolveStream(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.container.executeStreamWithPipeline(ctx, handler, request), nil
+}
+
+// invokeStreamHandler calls the Handle method of a stream request handler with reflection
+// It is only used for the definitions which are not created by NewStreamRequestHandlerDefinition
+func invokeStreamHandler(ctx context.Context, handler interface{}, request BaseRequest) iter.Seq2[interface{}, error] {
+	handlerMethod := reflect.ValueOf(handler).
+		MethodByName("Handle")
+	if !handlerMethod.IsValid() {
+		return errorSeq(fmt.Errorf("%w for stream request %T: %T has no Handle method", ErrInvalidHandler, request, handler))
 	}
+	// Call the method with ctx and request as arguments and get the returned sequence
+	result := handlerMethod.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(request)})
 
-	return s.container.executeWithPipeline(ctx, request, requestHandlerBehavior)
+	return func(yield func(interface{}, error) bool) {
+		for item, err := range result[0].Seq2() {
+			var itemErr error
+			if !err.IsNil() {
+				itemErr = err.Interface().(error)
+			}
+			if !yield(item.Interface(), itemErr) {
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// StreamWithoutContext sends a stream request to a single handler without a context
+func StreamWithoutContext[TRequest StreamRequest[TItem], TItem interface{}](container SendContainer,
+	request TRequest) (iter.Seq2[TItem, error], error) {
+	return Stream[TRequest, TItem](context.Background(), container, request)
+}
+
+// Stream sends a stream request to a single handler and returns the sequence of items it produces
+// The sequence is lazy: the handler and the stream pipeline behaviors run while it is iterated
+func Stream[TRequest StreamRequest[TItem], TItem interface{}](ctx context.Context,
+	container SendContainer,
+	request TRequest) (iter.Seq2[TItem, error], error) {
+	handler, resolvedRequest, err := container.resolveStream(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedSeq[TItem](container.executeStreamWithPipeline(ctx, handler, resolvedRequest)), nil
+}
+
+// untypedSeq converts a typed sequence to the sequence seen by the stream pipeline behaviors
+func untypedSeq[TItem interface{}](seq iter.Seq2[TItem, error]) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		for item, err := range seq {
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// typedSeq converts the sequence returned by the stream pipeline back to the typed sequence.
+// An item of an unexpected type ends the sequence with an error.
+func typedSeq[TItem interface{}](seq iter.Seq2[interface{}, error]) iter.Seq2[TItem, error] {
+	return func(yield func(TItem, error) bool) {
+		for item, err := range seq {
+			if item == nil {
+				if !yield(*new(TItem), err) {
+					return
+				}
+				continue
+			}
+			typedItem, ok := item.(TItem)
+			if !ok {
+				yield(*new(TItem), fmt.Errorf("stream item %T is not a %T", item, *new(TItem)))
+				return
+			}
+			if !yield(typedItem, err) {
+				return
+			}
+		}
+	}
+}
+
+func errorSeq(err error) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		yield(nil, err)
+	}
+}
+
+// compileStreamPipeline chains the stream behaviors in front of the handler, the first behavior being the outermost
+func compileStreamPipeline(behaviors []StreamPipelineBehavior, handler StreamNextFunc) StreamNextFunc {
+	next := handler
+	for i := len(behaviors) - 1; i >= 0; i-- {
+		behavior := behaviors[i]
+		inner := next
+		next = func(ctx context.Context, request BaseRequest) iter.Seq2[interface{}, error] {
+			return behavior.Handle(ctx, request, inner)
+		}
+	}
+	return next
+}
@@ -0,0 +1,24 @@
+package mediator
+
+import (
+	"context"
+	"iter"
+)
+
+// StreamPipelineBehavior is a marker interface for stream pipeline behaviors
+// A stream pipeline behavior wraps the sequence produced by a stream request handler,
+// e.g. to count the items, transform them or stop the iteration early
+type StreamPipelineBehavior interface {
+	Handle(ctx context.Context, request BaseRequest, next StreamNextFunc) iter.Seq2[interface{}, error]
+}
+
+// StreamNextFunc is a function that produces the sequence of the next step in a stream pipeline
+// The context and request given to it are forwarded to the rest of the pipeline
+type StreamNextFunc func(ctx context.Context, request BaseRequest) iter.Seq2[interface{}, error]
+
+// StreamPipelineBehaviorFunc is a function wrapping the sequence of a stream request, it is a StreamPipelineBehavior
+type StreamPipelineBehaviorFunc func(ctx context.Context, request BaseRequest, next StreamNextFunc) iter.Seq2[interface{}, error]
+
+func (f StreamPipelineBehaviorFunc) Handle(ctx context.Context, request BaseRequest, next StreamNextFunc) iter.Seq2[interface{}, error] {
+	return f(ctx, request, next)
+}
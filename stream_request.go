@@ -0,0 +1,25 @@
+package mediator
+
+import (
+	"context"
+	"iter"
+)
+
+// StreamRequest is a marker interface for stream requests
+// A stream request is a message that is sent to a single handler, returning a sequence of items
+type StreamRequest[TItem interface{}] interface {
+	BaseRequest
+}
+
+// StreamRequestHandler is a marker interface for stream request handlers
+// A stream request handler is a handler that handles a stream request and yields its items lazily
+type StreamRequestHandler[TRequest StreamRequest[TItem], TItem interface{}] interface {
+	Handle(ctx context.Context, request TRequest) iter.Seq2[TItem, error]
+}
+
+// StreamHandlerFunc is a function handling a stream request, it is a StreamRequestHandler
+type StreamHandlerFunc[TRequest StreamRequest[TItem], TItem interface{}] func(ctx context.Context, request TRequest) iter.Seq2[TItem, error]
+
+func (f StreamHandlerFunc[TRequest, TItem]) Handle(ctx context.Context, request TRequest) iter.Seq2[TItem, error] {
+	return f(ctx, request)
+}
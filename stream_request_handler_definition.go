@@ -0,0 +1,77 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// StreamRequestHandlerDefinition is a marker interface for stream request handler definitions
+// It is used to define a stream request handler and its associated request type
+type StreamRequestHandlerDefinition interface {
+	RequestType() reflect.Type
+	Handler() interface{}
+}
+
+// NewStreamRequestHandlerDefinition creates a new stream request handler definition
+func NewStreamRequestHandlerDefinition[TRequest StreamRequest[TItem], TItem interface{}](handler StreamRequestHandler[TRequest, TItem]) StreamRequestHandlerDefinition {
+	// TypeOf a pointer keeps the interface types, which have no dynamic type
+	requestType := reflect.TypeOf((*TRequest)(nil)).Elem()
+
+	return &TypedStreamRequestHandlerDefinition[TRequest, TItem]{
+		requestType: requestType,
+		handler:     handler,
+	}
+}
+
+type TypedStreamRequestHandlerDefinition[TRequest StreamRequest[TItem], TItem interface{}] struct {
+	requestType reflect.Type
+	handler     StreamRequestHandler[TRequest, TItem]
+}
+
+func (t TypedStreamRequestHandlerDefinition[TRequest, TItem]) Handler() interface{} {
+	return t.handler
+}
+
+func (t TypedStreamRequestHandlerDefinition[TRequest, TItem]) RequestType() reflect.Type {
+	return t.requestType
+}
+
+func (t TypedStreamRequestHandlerDefinition[TRequest, TItem]) invokeHandler(ctx context.Context, request BaseRequest) iter.Seq2[interface{}, error] {
+	typedRequest, ok := request.(TRequest)
+	if !ok {
+		return errorSeq(fmt.Errorf("stream request %T is not a %s", request, t.requestType))
+	}
+	return untypedSeq(t.handler.Handle(ctx, typedRequest))
+}
+
+// streamHandlerInvoker is implemented by the definitions calling their handler without reflection
+type streamHandlerInvoker interface {
+	invokeHandler(ctx context.Context, request BaseRequest) iter.Seq2[interface{}, error]
+}
+
+// streamHandlerEntry is a registered stream request handler with the function invoking it
+type streamHandlerEntry struct {
+	handler interface{}
+	invoke  StreamNextFunc
+	// pipeline is the stream pipeline behaviors of the container chained in front of invoke,
+	// it is compiled once when the handler is registered
+	pipeline StreamNextFunc
+}
+
+func newStreamHandlerEntry(definition StreamRequestHandlerDefinition) *streamHandlerEntry {
+	handler := definition.Handler()
+	entry := &streamHandlerEntry{
+		handler: handler,
+	}
+	if invoker, ok := definition.(streamHandlerInvoker); ok {
+		entry.invoke = invoker.invokeHandler
+	} else {
+		// The definition is not generic, the handler can only be called with reflection
+		entry.invoke = func(ctx context.Context, request BaseRequest) iter.Seq2[interface{}, error] {
+			return invokeStreamHandler(ctx, handler, request)
+		}
+	}
+	return entry
+}
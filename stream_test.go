@@ -0,0 +1,223 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"iter"
+	"testing"
+)
+
+type TestStreamRequest struct {
+	Count int
+}
+
+func (t TestStreamRequest) String() string {
+	return "TestStreamRequest"
+}
+
+type TestStreamRequestHandler struct {
+	err error
+}
+
+func (t TestStreamRequestHandler) Handle(ctx context.Context, request TestStreamRequest) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for i := 0; i < request.Count; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+		if t.err != nil {
+			yield(0, t.err)
+		}
+	}
+}
+
+type TestCountedRequest interface {
+	mediator.BaseRequest
+	Total() int
+}
+
+func (t TestStreamRequest) Total() int {
+	return t.Count
+}
+
+type TestCountedRequestHandler struct {
+}
+
+func (t TestCountedRequestHandler) Handle(ctx context.Context, request TestCountedRequest) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		yield(request.Total(), nil)
+	}
+}
+
+type TestCountStreamPipelineBehavior struct {
+	Count int
+}
+
+func (t *TestCountStreamPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.StreamNextFunc) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		for item, err := range next(ctx, request) {
+			t.Count++
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+type TestLimitStreamPipelineBehavior struct {
+	Limit int
+}
+
+func (t TestLimitStreamPipelineBehavior) Handle(ctx context.Context, request mediator.BaseRequest, next mediator.StreamNextFunc) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		count := 0
+		for item, err := range next(ctx, request) {
+			if count >= t.Limit {
+				return
+			}
+			count++
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+func collectStream[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func TestStream(t *testing.T) {
+	t.Run("should stream the items of the handler", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 3})
+		assert.NoError(t, err)
+
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1, 2}, items)
+	})
+
+	t.Run("should return an error without handler", func(t *testing.T) {
+		container := mediator.NewSendContainer()
+
+		_, err := mediator.StreamWithoutContext[TestStreamRequest, int](container, TestStreamRequest{Count: 3})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should yield the handler error", func(t *testing.T) {
+		handlerErr := errors.New("page failed")
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{err: handlerErr})),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 2})
+		assert.NoError(t, err)
+
+		items, err := collectStream(seq)
+		assert.ErrorIs(t, err, handlerErr)
+		assert.Equal(t, []int{0, 1}, items)
+	})
+
+	t.Run("should apply stream pipeline behaviors in order", func(t *testing.T) {
+		counter := &TestCountStreamPipelineBehavior{}
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+			mediator.WithStreamPipelineBehavior(TestLimitStreamPipelineBehavior{Limit: 2}),
+			mediator.WithStreamPipelineBehavior(counter),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 10})
+		assert.NoError(t, err)
+
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, items)
+		assert.Equal(t, 3, counter.Count)
+	})
+
+	t.Run("should stream through the sender", func(t *testing.T) {
+		counter := &TestCountStreamPipelineBehavior{}
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+			mediator.WithStreamPipelineBehavior(counter),
+		)
+		sender := mediator.NewSender(container)
+
+		seq, err := sender.Stream(context.Background(), TestStreamRequest{Count: 3})
+		assert.NoError(t, err)
+
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0, 1, 2}, items)
+		assert.Equal(t, 3, counter.Count)
+	})
+
+	t.Run("should stream from a function handler through a function behavior", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](
+				mediator.StreamHandlerFunc[TestStreamRequest, int](func(ctx context.Context, request TestStreamRequest) iter.Seq2[int, error] {
+					return func(yield func(int, error) bool) {
+						for i := request.Count; i > 0; i-- {
+							if !yield(i, nil) {
+								return
+							}
+						}
+					}
+				}))),
+			mediator.WithStreamPipelineBehavior(mediator.StreamPipelineBehaviorFunc(
+				func(ctx context.Context, request mediator.BaseRequest, next mediator.StreamNextFunc) iter.Seq2[interface{}, error] {
+					return next(ctx, TestStreamRequest{Count: request.(TestStreamRequest).Count - 1})
+				})),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 3})
+		assert.NoError(t, err)
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 1}, items)
+	})
+	t.Run("should resolve a stream request sent by pointer to the handler of its value type", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+		)
+
+		seq, err := mediator.Stream[*TestStreamRequest, int](context.Background(), container, &TestStreamRequest{Count: 2})
+		assert.NoError(t, err)
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, items)
+
+		seq2, err := mediator.NewSender(container).Stream(context.Background(), &TestStreamRequest{Count: 1})
+		assert.NoError(t, err)
+		items2, err := collectStream(seq2)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{0}, items2)
+	})
+
+	t.Run("should resolve a stream request to the handler of an interface it implements", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestCountedRequest, int](TestCountedRequestHandler{})),
+			mediator.WithInterfaceRequestResolution(),
+		)
+
+		seq, err := mediator.Stream[TestStreamRequest, int](context.Background(), container, TestStreamRequest{Count: 5})
+		assert.NoError(t, err)
+		items, err := collectStream(seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{5}, items)
+	})
+}
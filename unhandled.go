@@ -0,0 +1,91 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// FallbackRequestHandler handles the requests without handler, e.g. to forward them to another service
+// It runs through the pipeline behaviors like the other handlers
+type FallbackRequestHandler interface {
+	Handle(ctx context.Context, request BaseRequest) (interface{}, error)
+}
+
+// FallbackRequestHandlerFunc is a function handling the requests without handler, it is a FallbackRequestHandler
+type FallbackRequestHandlerFunc func(ctx context.Context, request BaseRequest) (interface{}, error)
+
+func (f FallbackRequestHandlerFunc) Handle(ctx context.Context, request BaseRequest) (interface{}, error) {
+	return f(ctx, request)
+}
+
+// WithFallbackRequestHandler sets the handler of the requests without handler,
+// instead of failing with ErrNoHandler
+func WithFallbackRequestHandler(handler FallbackRequestHandler) func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.FallbackRequestHandler = handler
+	}
+}
+
+// fallbackRequestHandlerDefinition is the definition of the fallback request handler, registered for BaseRequest
+type fallbackRequestHandlerDefinition struct {
+	handler FallbackRequestHandler
+}
+
+func (f fallbackRequestHandlerDefinition) RequestType() reflect.Type {
+	return baseRequestType
+}
+
+func (f fallbackRequestHandlerDefinition) Handler() interface{} {
+	return f.handler
+}
+
+func (f fallbackRequestHandlerDefinition) invokeHandler(ctx context.Context, request BaseRequest) (interface{}, error) {
+	return f.handler.Handle(ctx, request)
+}
+
+// UnhandledNotificationPolicy is the way to publish the notifications without handler
+type UnhandledNotificationPolicy int
+
+const (
+	// IgnoreUnhandledNotification publishes the notifications without handler without error, it is the default
+	IgnoreUnhandledNotification UnhandledNotificationPolicy = iota
+	// FailUnhandledNotification fails the publication of the notifications without handler with ErrNoHandler
+	FailUnhandledNotification
+	// DeadLetterUnhandledNotification gives the notifications without handler to the dead letter handler
+	DeadLetterUnhandledNotification
+)
+
+// DeadLetterHandler receives the notifications without handler, e.g. to store them until their handler is registered
+// Its error is returned by the publication
+type DeadLetterHandler func(ctx context.Context, notification interface{}) error
+
+// WithUnhandledNotificationPolicy sets the way to publish the notifications without handler
+func WithUnhandledNotificationPolicy(policy UnhandledNotificationPolicy) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.UnhandledNotificationPolicy = policy
+	}
+}
+
+// WithDeadLetterHandler gives the notifications without handler to the dead letter handler
+func WithDeadLetterHandler(handler DeadLetterHandler) func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.UnhandledNotificationPolicy = DeadLetterUnhandledNotification
+		options.DeadLetterHandler = handler
+	}
+}
+
+// publishUnhandled applies the unhandled notification policy to a notification without handler
+func (n *notificationContainer) publishUnhandled(ctx context.Context, notification interface{}) error {
+	switch n.unhandledPolicy {
+	case FailUnhandledNotification:
+		return fmt.Errorf("%w for notification %T", ErrNoHandler, notification)
+	case DeadLetterUnhandledNotification:
+		if n.deadLetter == nil {
+			return fmt.Errorf("%w for notification %T: no dead letter handler", ErrNoHandler, notification)
+		}
+		return n.deadLetter(ctx, notification)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package mediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestForwardRequestHandler struct {
+	forwarded []mediator.BaseRequest
+}
+
+func (t *TestForwardRequestHandler) Handle(ctx context.Context, request mediator.BaseRequest) (interface{}, error) {
+	t.forwarded = append(t.forwarded, request)
+	return "forwarded " + request.String(), nil
+}
+
+func TestFallbackRequestHandler(t *testing.T) {
+	t.Run("should send the requests without handler to the fallback handler", func(t *testing.T) {
+		fallback := &TestForwardRequestHandler{}
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithFallbackRequestHandler(fallback),
+			mediator.WithContextPipelineBehavior(mediator.ContextPipelineBehaviorFunc(func(ctx context.Context, request mediator.BaseRequest, next mediator.NextFunc) (interface{}, error) {
+				response, err := next(ctx, request)
+				return response.(string) + "!", err
+			})),
+		)
+		assert.NoError(t, container.Validate())
+
+		response, err := mediator.Send[*TestRequest, string](context.Background(), container, &TestRequest{Value: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test!", response)
+		assert.Empty(t, fallback.forwarded)
+
+		response2, err := mediator.NewSender(container).Send(context.Background(), TestGreetRequest{Value: "john"})
+		assert.NoError(t, err)
+		assert.Equal(t, "forwarded TestGreetRequest!", response2)
+		assert.Equal(t, []mediator.BaseRequest{TestGreetRequest{Value: "john"}}, fallback.forwarded)
+	})
+
+	t.Run("should fail when the response of the fallback handler is not the response of the request", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithFallbackRequestHandler(mediator.FallbackRequestHandlerFunc(func(ctx context.Context, request mediator.BaseRequest) (interface{}, error) {
+				return "unexpected", nil
+			})),
+		)
+
+		_, err := mediator.Send[TestOtherRequest, int](context.Background(), container, TestOtherRequest{})
+		var mismatchErr *mediator.ResponseTypeMismatchError
+		assert.ErrorAs(t, err, &mismatchErr)
+	})
+
+	t.Run("should report the error of the fallback handler as a handler error", func(t *testing.T) {
+		forwardErr := errors.New("service unavailable")
+		container := mediator.NewSendContainer(
+			mediator.WithFallbackRequestHandler(mediator.FallbackRequestHandlerFunc(func(ctx context.Context, request mediator.BaseRequest) (interface{}, error) {
+				return nil, forwardErr
+			})),
+		)
+
+		_, err := mediator.Send[TestOtherRequest, int](context.Background(), container, TestOtherRequest{})
+		assert.ErrorIs(t, err, forwardErr)
+		var dispatchErr *mediator.DispatchError
+		assert.ErrorAs(t, err, &dispatchErr)
+		assert.Equal(t, mediator.StageHandler, dispatchErr.Stage)
+	})
+}
+
+func TestUnhandledNotificationPolicy(t *testing.T) {
+	t.Run("should ignore the notifications without handler by default", func(t *testing.T) {
+		container := mediator.NewPublishContainer()
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+	})
+
+	t.Run("should fail for the notifications without handler", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithUnhandledNotificationPolicy(mediator.FailUnhandledNotification),
+		)
+
+		err := mediator.PublishWithoutContext(container, TestNotification{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+		err = mediator.NewPublisher(container).Publish(context.Background(), TestNotification{Value: "test"})
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+	})
+
+	t.Run("should give the notifications without handler to the dead letter handler", func(t *testing.T) {
+		var deadLetters []interface{}
+		handler := &TestNotificationHandler{}
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](handler)),
+			mediator.WithDeadLetterHandler(func(ctx context.Context, notification interface{}) error {
+				deadLetters = append(deadLetters, notification)
+				return nil
+			}),
+		)
+		assert.NoError(t, container.Validate())
+
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestNotification{Value: "test"}))
+		assert.NoError(t, mediator.PublishWithoutContext(container, TestUserCreated{ID: "1"}))
+		assert.True(t, handler.Executed)
+		assert.Equal(t, []interface{}{TestUserCreated{ID: "1"}}, deadLetters)
+	})
+
+	t.Run("should not validate the dead letter policy without handler", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithUnhandledNotificationPolicy(mediator.DeadLetterUnhandledNotification),
+		)
+
+		assert.ErrorIs(t, container.Validate(), mediator.ErrInvalidHandler)
+		assert.ErrorIs(t, mediator.PublishWithoutContext(container, TestNotification{}), mediator.ErrNoHandler)
+	})
+}
@@ -0,0 +1,223 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrDuplicateHandler is the error of a request type registered with several handlers
+var ErrDuplicateHandler = errors.New("mediator: duplicate handler")
+
+// ErrInvalidBehavior is the error of a nil behavior or processor
+var ErrInvalidBehavior = errors.New("mediator: invalid behavior")
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// handlerValidator is implemented by the definitions whose handler is not called through a Handle method,
+// they validate their handler themselves
+type handlerValidator interface {
+	validateHandler() error
+}
+
+// WithStrictRequestValidation validates the container when it is created, NewSendContainer panics if it is invalid
+func WithStrictRequestValidation() func(*SendContainerOptions) {
+	return func(options *SendContainerOptions) {
+		options.StrictValidation = true
+	}
+}
+
+// WithStrictNotificationValidation validates the container when it is created, NewPublishContainer panics if it is invalid
+func WithStrictNotificationValidation() func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.StrictValidation = true
+	}
+}
+
+// WithRequiredNotification declares a notification type which must have at least one handler,
+// it is checked by the validation of the container
+func WithRequiredNotification[TNotification Notification]() func(*PublishOptions) {
+	return func(options *PublishOptions) {
+		options.RequiredNotificationTypes = append(options.RequiredNotificationTypes,
+			reflect.TypeOf((*TNotification)(nil)).Elem())
+	}
+}
+
+// Validate reports the request types registered with several handlers, the nil handlers and behaviors,
+// and the handlers whose Handle method does not match their definition
+func (c *sendContainer) Validate() error {
+	var errs []error
+	state := c.state.Load()
+
+	registrations := make(map[reflect.Type]int, len(state.registrations.definitions))
+	for _, definition := range state.registrations.definitions.values() {
+		requestType := definition.RequestType()
+		registrations[requestType]++
+		if registrations[requestType] == 2 {
+			errs = append(errs, fmt.Errorf("%w for request %s", ErrDuplicateHandler, requestType))
+		}
+		var responseType reflect.Type
+		if typed, ok := definition.(responseTypeDefinition); ok {
+			responseType = typed.ResponseType()
+		}
+		if validator, ok := definition.(handlerValidator); ok {
+			if err := validator.validateHandler(); err != nil {
+				errs = append(errs, fmt.Errorf("%w for request %s: %w", ErrInvalidHandler, requestType, err))
+			}
+			continue
+		}
+		if err := validateRequestHandler(definition.Handler(), requestType, responseType); err != nil {
+			errs = append(errs, fmt.Errorf("%w for request %s: %w", ErrInvalidHandler, requestType, err))
+		}
+	}
+
+	streamRegistrations := make(map[reflect.Type]int, len(state.registrations.streamDefinitions))
+	for _, definition := range state.registrations.streamDefinitions.values() {
+		requestType := definition.RequestType()
+		streamRegistrations[requestType]++
+		if streamRegistrations[requestType] == 2 {
+			errs = append(errs, fmt.Errorf("%w for stream request %s", ErrDuplicateHandler, requestType))
+		}
+		if err := validateStreamHandler(definition.Handler(), requestType); err != nil {
+			errs = append(errs, fmt.Errorf("%w for stream request %s: %w", ErrInvalidHandler, requestType, err))
+		}
+	}
+
+	if c.fallback != nil && isNil(c.fallback) {
+		errs = append(errs, fmt.Errorf("%w: fallback request handler is nil", ErrInvalidHandler))
+	}
+
+	for i, behavior := range state.pipelines {
+		if isNil(userBehavior(behavior)) {
+			errs = append(errs, fmt.Errorf("%w: pipeline behavior #%d is nil", ErrInvalidBehavior, i))
+		}
+	}
+	for i, behavior := range state.registrations.streamBehaviors.values() {
+		if isNil(behavior) {
+			errs = append(errs, fmt.Errorf("%w: stream pipeline behavior #%d is nil", ErrInvalidBehavior, i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports the nil handlers and behaviors, the handlers whose Handle method does not match their definition,
+// and the required notification types without handlers
+func (n *notificationContainer) Validate() error {
+	var errs []error
+	state := n.state.Load()
+
+	for _, definition := range state.registrations.definitions.values() {
+		notificationType := definition.NotificationType()
+		if validator, ok := definition.(handlerValidator); ok {
+			if err := validator.validateHandler(); err != nil {
+				errs = append(errs, fmt.Errorf("%w for notification %s: %w", ErrInvalidHandler, notificationType, err))
+			}
+			continue
+		}
+		if err := validateNotificationHandler(definition.Handler(), notificationType); err != nil {
+			errs = append(errs, fmt.Errorf("%w for notification %s: %w", ErrInvalidHandler, notificationType, err))
+		}
+	}
+	if n.unhandledPolicy == DeadLetterUnhandledNotification && n.deadLetter == nil {
+		errs = append(errs, fmt.Errorf("%w: dead letter handler is nil", ErrInvalidHandler))
+	}
+	for _, notificationType := range n.requiredTypes {
+		if state.resolveType(notificationType) == nil {
+			errs = append(errs, fmt.Errorf("%w for notification %s: it is required", ErrNoHandler, notificationType))
+		}
+	}
+
+	for i, behavior := range state.behaviors {
+		if isNil(behavior) {
+			errs = append(errs, fmt.Errorf("%w: notification behavior #%d is nil", ErrInvalidBehavior, i))
+		}
+	}
+	for i, behavior := range state.handlerBehaviors {
+		if isNil(behavior) {
+			errs = append(errs, fmt.Errorf("%w: notification handler behavior #%d is nil", ErrInvalidBehavior, i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateRequestHandler(handler interface{}, requestType reflect.Type, responseType reflect.Type) error {
+	method, err := handleMethod(handler, requestType)
+	if err != nil {
+		return err
+	}
+	if method.NumOut() != 2 || method.Out(1) != errorType ||
+		(responseType != nil && !method.Out(0).AssignableTo(responseType)) {
+		return fmt.Errorf("%T.Handle does not return a response and an error", handler)
+	}
+	return nil
+}
+
+func validateStreamHandler(handler interface{}, requestType reflect.Type) error {
+	method, err := handleMethod(handler, requestType)
+	if err != nil {
+		return err
+	}
+	if method.NumOut() != 1 || !isErrorSeq(method.Out(0)) {
+		return fmt.Errorf("%T.Handle does not return an iter.Seq2 of items and errors", handler)
+	}
+	return nil
+}
+
+func validateNotificationHandler(handler interface{}, notificationType reflect.Type) error {
+	method, err := handleMethod(handler, notificationType)
+	if err != nil {
+		return err
+	}
+	if method.NumOut() != 1 || method.Out(0) != errorType {
+		return fmt.Errorf("%T.Handle does not return an error", handler)
+	}
+	return nil
+}
+
+// handleMethod returns the type of the Handle method of the handler,
+// after checking it accepts a context and a message of the given type
+func handleMethod(handler interface{}, messageType reflect.Type) (reflect.Type, error) {
+	if isNil(handler) {
+		return nil, errors.New("handler is nil")
+	}
+	method, ok := reflect.TypeOf(handler).MethodByName("Handle")
+	if !ok {
+		return nil, fmt.Errorf("%T has no Handle method", handler)
+	}
+	// The first input of the method type is the receiver
+	methodType := method.Type
+	if methodType.NumIn() != 3 || methodType.In(1) != contextType || !messageType.AssignableTo(methodType.In(2)) {
+		return nil, fmt.Errorf("%T.Handle does not accept a context and a %s", handler, messageType)
+	}
+	return methodType, nil
+}
+
+// isErrorSeq reports whether the type is an iter.Seq2 whose second value is an error
+func isErrorSeq(seqType reflect.Type) bool {
+	if seqType.Kind() != reflect.Func || seqType.NumIn() != 1 || seqType.NumOut() != 0 {
+		return false
+	}
+	yieldType := seqType.In(0)
+	return yieldType.Kind() == reflect.Func &&
+		yieldType.NumIn() == 2 && yieldType.In(1) == errorType &&
+		yieldType.NumOut() == 1 && yieldType.Out(0).Kind() == reflect.Bool
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+package mediator_test
+
+import (
+	"github.com/Oleexo/mediator-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSendContainerValidate(t *testing.T) {
+	t.Run("should accept a valid container", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandlers(
+				mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{}),
+				mediator.NewRequestHandlerDefinition[TestOtherRequest, int](TestOtherRequestHandler{}),
+			),
+			mediator.WithStreamRequestDefinitionHandler(mediator.NewStreamRequestHandlerDefinition[TestStreamRequest, int](TestStreamRequestHandler{})),
+			mediator.WithPipelineBehavior(TestPipelineBehavior{}),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+		)
+		assert.NoError(t, container.Validate())
+	})
+
+	t.Run("should report the duplicate request handlers", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandlers(
+				mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{}),
+				mediator.NewRequestHandlerDefinition[*TestRequest, string](TestContextRequestHandler{}),
+			),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrDuplicateHandler)
+		assert.EqualError(t, err, "mediator: duplicate handler for request *mediator_test.TestRequest")
+	})
+
+	t.Run("should report the nil handlers", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](nil)),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+		assert.EqualError(t, err, "mediator: invalid handler for request *mediator_test.TestRequest: handler is nil")
+	})
+
+	t.Run("should report the handlers not matching their definition", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(testCustomRequestHandlerDefinition{handler: TestOtherRequestHandler{}}),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+		assert.EqualError(t, err, "mediator: invalid handler for request *mediator_test.TestRequest: mediator_test.TestOtherRequestHandler.Handle does not accept a context and a *mediator_test.TestRequest")
+
+		container = mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(testCustomRequestHandlerDefinition{handler: struct{}{}}),
+		)
+		assert.ErrorIs(t, container.Validate(), mediator.ErrInvalidHandler)
+	})
+
+	t.Run("should report the nil behaviors", func(t *testing.T) {
+		container := mediator.NewSendContainer(
+			mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+			mediator.WithContextPipelineBehavior(TestContextPipelineBehavior{}),
+			mediator.WithPipelineBehavior(nil),
+			mediator.WithStreamPipelineBehavior(nil),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidBehavior)
+		assert.EqualError(t, err, "mediator: invalid behavior: pipeline behavior #1 is nil\n"+
+			"mediator: invalid behavior: stream pipeline behavior #0 is nil")
+	})
+
+	t.Run("should panic on an invalid container when strict", func(t *testing.T) {
+		assert.Panics(t, func() {
+			mediator.NewSendContainer(
+				mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](nil)),
+				mediator.WithStrictRequestValidation(),
+			)
+		})
+		assert.NotPanics(t, func() {
+			mediator.NewSendContainer(
+				mediator.WithRequestDefinitionHandler(mediator.NewRequestHandlerDefinition[*TestRequest, string](TestRequestHandler{})),
+				mediator.WithStrictRequestValidation(),
+			)
+		})
+	})
+}
+
+func TestPublishContainerValidate(t *testing.T) {
+	t.Run("should accept a valid container", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandlers(
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{}),
+				mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler2{}),
+				testCustomNotificationHandlerDefinition{handler: &TestNotificationHandler{}},
+			),
+			mediator.WithRequiredNotification[TestNotification](),
+		)
+
+		assert.NoError(t, container.Validate())
+	})
+
+	t.Run("should report the required notifications without handlers", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestNotification](&TestNotificationHandler{})),
+			mediator.WithRequiredNotification[TestUserCreated](),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrNoHandler)
+		assert.EqualError(t, err, "mediator: no handler for notification mediator_test.TestUserCreated: it is required")
+	})
+
+	t.Run("should accept a required notification handled by an interface handler", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(mediator.NewNotificationHandlerDefinition[TestDomainEvent](&TestAuditHandler{})),
+			mediator.WithRequiredNotification[TestUserCreated](),
+		)
+
+		assert.NoError(t, container.Validate())
+	})
+
+	t.Run("should report the handlers not matching their definition", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationDefinitionHandler(testCustomNotificationHandlerDefinition{handler: struct{}{}}),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidHandler)
+		assert.EqualError(t, err, "mediator: invalid handler for notification mediator_test.TestNotification: struct {} has no Handle method")
+	})
+
+	t.Run("should report the nil behaviors", func(t *testing.T) {
+		container := mediator.NewPublishContainer(
+			mediator.WithNotificationBehavior(nil),
+			mediator.WithNotificationHandlerBehavior(nil),
+		)
+
+		err := container.Validate()
+		assert.ErrorIs(t, err, mediator.ErrInvalidBehavior)
+	})
+
+	t.Run("should panic on an invalid container when strict", func(t *testing.T) {
+		assert.Panics(t, func() {
+			mediator.NewPublishContainer(
+				mediator.WithRequiredNotification[TestNotification](),
+				mediator.WithStrictNotificationValidation(),
+			)
+		})
+	})
+}